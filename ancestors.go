@@ -0,0 +1,94 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+//Returns n if it is matched by m, otherwise the closest ancestor of n that is matched by m.
+//Returns nil if no such node was found.
+func Closest(n *html.Node, m Matcher) *html.Node {
+	return ClosestWithin(n, nil, m)
+}
+
+//Like Closest, but the search ends before boundary is examined.
+//A nil boundary lets the search continue up to the root of the tree.
+func ClosestWithin(n, boundary *html.Node, m Matcher) *html.Node {
+	if n == boundary {
+		return nil
+	}
+	if m(n) {
+		return n
+	}
+	return firstAncestor(n, boundary, m)
+}
+
+//Returns the closest ancestor of n that matches at least one of the given tags. Returns nil if no such element was found.
+func ParentByTag(n *html.Node, tag ...atom.Atom) *html.Node {
+	return ParentByTagWithin(n, nil, tag...)
+}
+
+//Like ParentByTag, but the search ends before boundary is examined, boundary may be nil.
+func ParentByTagWithin(n, boundary *html.Node, tag ...atom.Atom) *html.Node {
+	return firstAncestor(n, boundary, Tag(tag...))
+}
+
+//Returns the closest ancestor of n that is a member of all given classes. Returns nil if no such element was found.
+func ParentByClassName(n *html.Node, name ...string) *html.Node {
+	return ParentByClassNameWithin(n, nil, name...)
+}
+
+//Like ParentByClassName, but the search ends before boundary is examined, boundary may be nil.
+func ParentByClassNameWithin(n, boundary *html.Node, name ...string) *html.Node {
+	return firstAncestor(n, boundary, Class(name...))
+}
+
+//Returns all ancestors of n that contain all given attributes, starting with the closest one.
+//Returns nil if no matches were found.
+func AncestorsByAttr(n *html.Node, attr ...html.Attribute) []*html.Node {
+	return AncestorsMatchingWithin(n, nil, Attr(attr...))
+}
+
+//Like AncestorsByAttr, but the search ends before boundary is examined, boundary may be nil.
+func AncestorsByAttrWithin(n, boundary *html.Node, attr ...html.Attribute) []*html.Node {
+	return AncestorsMatchingWithin(n, boundary, Attr(attr...))
+}
+
+//Returns all ancestors of n that are matched by m, starting with the closest one. Returns nil if no matches were found.
+func AncestorsMatching(n *html.Node, m Matcher) []*html.Node {
+	return AncestorsMatchingWithin(n, nil, m)
+}
+
+//Like AncestorsMatching, but the search ends before boundary is examined, boundary may be nil.
+func AncestorsMatchingWithin(n, boundary *html.Node, m Matcher) []*html.Node {
+	if n == boundary {
+		return nil
+	}
+	var nodes []*html.Node
+	for a := range Ancestors(n) {
+		if a == boundary {
+			break
+		}
+		if m(a) {
+			nodes = append(nodes, a)
+		}
+	}
+	return nodes
+}
+
+func firstAncestor(n, boundary *html.Node, m Matcher) *html.Node {
+	if n == boundary {
+		return nil
+	}
+	for a := range Ancestors(n) {
+		if a == boundary {
+			break
+		}
+		if m(a) {
+			return a
+		}
+	}
+	return nil
+}
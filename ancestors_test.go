@@ -0,0 +1,77 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"testing"
+)
+
+func TestClosest(t *testing.T) {
+	const testDoc = "ancestors.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	price := ElementByID(root, "price")
+	table := ElementByID(root, "prices")
+
+	expectID := func(name string, n *html.Node, id string) {
+		t.Helper()
+		if n == nil {
+			t.Errorf("%s: expected element \"%s\", got nil", name, id)
+		} else if got := AttrVal(n, "", "id"); got != id {
+			t.Errorf("%s: expected element \"%s\", got \"%s\"", name, id, got)
+		}
+	}
+
+	expectID("Closest self", Closest(price, Tag(atom.Span)), "price")
+	expectID("Closest", Closest(price, Class("product")), "inner")
+	expectID("ClosestWithin", ClosestWithin(price, table, Tag(atom.Tr)), "row")
+	if ClosestWithin(price, table, Tag(atom.Table)) != nil {
+		t.Error("ClosestWithin must not examine the boundary")
+	}
+	if ClosestWithin(price, price, Tag(atom.Span)) != nil {
+		t.Error("ClosestWithin must not examine the boundary")
+	}
+
+	expectID("ParentByTag", ParentByTag(price, atom.Td, atom.Tr), "cell")
+	expectID("ParentByTagWithin", ParentByTagWithin(price, table, atom.Tr), "row")
+	if ParentByTag(price, atom.Span) != nil {
+		t.Error("ParentByTag must not return the start node")
+	}
+	if ParentByTagWithin(price, table, atom.Div, atom.Body) == nil || ParentByTagWithin(ElementByID(root, "cell"), table, atom.Div) != nil {
+		t.Error("ParentByTagWithin does not respect the boundary")
+	}
+
+	expectID("ParentByClassName", ParentByClassName(price, "product"), "inner")
+	expectID("ParentByClassName", ParentByClassName(ElementByID(root, "inner"), "product"), "outer")
+	if ParentByClassNameWithin(price, ElementByID(root, "inner"), "product") != nil {
+		t.Error("ParentByClassNameWithin must not examine the boundary")
+	}
+}
+
+func TestAncestorsByAttr(t *testing.T) {
+	const testDoc = "ancestors.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	price := ElementByID(root, "price")
+	box := html.Attribute{Key: "data-kind", Val: "box"}
+
+	expectIDs(t, "AncestorsByAttr", AncestorsByAttr(price, box), "inner", "outer")
+	expectIDs(t, "AncestorsByAttrWithin", AncestorsByAttrWithin(price, ElementByID(root, "prices"), box), "inner")
+	expectIDs(t, "AncestorsMatching", AncestorsMatching(price, Tag(atom.Td, atom.Tr, atom.Table)), "cell", "row", "prices")
+	if AncestorsByAttr(price, html.Attribute{Key: "foo", Val: "bar"}) != nil {
+		t.Error("Expected return value nil")
+	}
+	if AncestorsMatchingWithin(price, price, Tag(atom.Div)) != nil {
+		t.Error("Expected return value nil if the start node is the boundary")
+	}
+}
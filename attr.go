@@ -0,0 +1,150 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/cond"
+	"golang.org/x/net/html"
+	"slices"
+	"strings"
+)
+
+//Sets the value of the attribute of n with the given namespace and key to val, the attribute is appended if n does not have it.
+//Like AttrVal, namespace and key are compared case-sensitively.
+func SetAttr(n *html.Node, namespace, key, val string) {
+	for i, a := range n.Attr {
+		if a.Namespace == namespace && a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Namespace: namespace, Key: key, Val: val})
+}
+
+//Removes the attribute of n with the given namespace and key. Returns false if n does not have such an attribute.
+func RemoveAttr(n *html.Node, namespace, key string) bool {
+	removed := false
+	attr := n.Attr[:0]
+	for _, a := range n.Attr {
+		if a.Namespace == namespace && a.Key == key {
+			removed = true
+			continue
+		}
+		attr = append(attr, a)
+	}
+	n.Attr = attr
+	return removed
+}
+
+//Removes the attribute of n with the given namespace and key if n has it, otherwise adds it with an empty value.
+//Returns true if n has the attribute afterwards.
+func ToggleAttr(n *html.Node, namespace, key string) bool {
+	if RemoveAttr(n, namespace, key) {
+		return false
+	}
+	SetAttr(n, namespace, key, "")
+	return true
+}
+
+//ClassList gives access to the class attribute of an element as a set of class names, like the DOM's classList.
+//Class names are separated by ASCII whitespace. Modifying methods rewrite the class attribute with the remaining names
+//separated by single spaces and duplicates removed. Names that are empty or contain whitespace are ignored.
+type ClassList struct {
+	n *html.Node
+}
+
+//Returns the ClassList of the element n.
+func Classes(n *html.Node) ClassList {
+	return ClassList{n}
+}
+
+//Returns the class names of the element in order, without duplicates. Returns nil if the element has no classes.
+func (c ClassList) Values() []string {
+	var names []string
+	for _, a := range c.n.Attr {
+		if a.Namespace == "" && a.Key == "class" {
+			for _, t := range cond.Tokens(a.Val) {
+				if !slices.Contains(names, t) {
+					names = append(names, t)
+				}
+			}
+			break
+		}
+	}
+	return names
+}
+
+//Reports whether the element is a member of the class name.
+func (c ClassList) Contains(name string) bool {
+	return slices.Contains(c.Values(), name)
+}
+
+//Adds the given class names that the element is not a member of yet.
+func (c ClassList) Add(name ...string) {
+	names := c.Values()
+	for _, s := range name {
+		if validClassName(s) && !slices.Contains(names, s) {
+			names = append(names, s)
+		}
+	}
+	c.update(names)
+}
+
+//Removes the given class names.
+func (c ClassList) Remove(name ...string) {
+	names := c.Values()
+	kept := names[:0]
+	for _, s := range names {
+		if !slices.Contains(name, s) {
+			kept = append(kept, s)
+		}
+	}
+	c.update(kept)
+}
+
+//Removes the class name if the element is a member, otherwise adds it. Returns true if the element is a member afterwards.
+func (c ClassList) Toggle(name string) bool {
+	if !validClassName(name) {
+		return false
+	}
+	if c.Contains(name) {
+		c.Remove(name)
+		return false
+	}
+	c.Add(name)
+	return true
+}
+
+//Replaces the class name old by replacement, keeping its position. Returns false if the element is not a member of old.
+func (c ClassList) Replace(old, replacement string) bool {
+	if !validClassName(replacement) {
+		return false
+	}
+	names := c.Values()
+	if !slices.Contains(names, old) {
+		return false
+	}
+	replaced := make([]string, 0, len(names))
+	for _, s := range names {
+		switch {
+		case s == old && !slices.Contains(replaced, replacement):
+			replaced = append(replaced, replacement)
+		case s != old && s != replacement || s == replacement && !slices.Contains(replaced, replacement):
+			replaced = append(replaced, s)
+		}
+	}
+	c.update(replaced)
+	return true
+}
+
+//Writes names to the class attribute, which is not created if there are no names.
+func (c ClassList) update(names []string) {
+	if !HasAttr(c.n, "", "class") && len(names) == 0 {
+		return
+	}
+	SetAttr(c.n, "", "class", strings.Join(names, " "))
+}
+
+func validClassName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, asciiWhitespace)
+}
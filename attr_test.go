@@ -0,0 +1,78 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestSetAttr(t *testing.T) {
+	n := &html.Node{Type: html.ElementNode, DataAtom: atom.A, Data: "a", Attr: []html.Attribute{{Key: "href", Val: "/"}}}
+	SetAttr(n, "", "href", "/new")
+	SetAttr(n, "", "title", "Title")
+	SetAttr(n, "xlink", "href", "/xlink")
+	if AttrVal(n, "", "href") != "/new" || AttrVal(n, "", "title") != "Title" || AttrVal(n, "xlink", "href") != "/xlink" || len(n.Attr) != 3 {
+		t.Errorf("Unexpected attributes %v", n.Attr)
+	}
+	if !RemoveAttr(n, "", "href") || RemoveAttr(n, "", "href") || AttrVal(n, "xlink", "href") != "/xlink" || len(n.Attr) != 2 {
+		t.Errorf("Unexpected attributes after removal %v", n.Attr)
+	}
+	if !ToggleAttr(n, "", "hidden") || !HasAttr(n, "", "hidden") {
+		t.Error("ToggleAttr must add a missing attribute")
+	}
+	if ToggleAttr(n, "", "hidden") || HasAttr(n, "", "hidden") {
+		t.Error("ToggleAttr must remove an existing attribute")
+	}
+}
+
+func TestClassList(t *testing.T) {
+	n := &html.Node{Type: html.ElementNode, DataAtom: atom.P, Data: "p"}
+	c := Classes(n)
+	c.Remove("a")
+	if HasAttr(n, "", "class") || c.Values() != nil {
+		t.Error("Removing from an empty class list must not create the class attribute")
+	}
+
+	SetAttr(n, "", "class", " a\tb\nc\f a b ")
+	tests := []struct {
+		op     func() bool
+		result bool
+		expect string
+	}{
+		{func() bool { return c.Contains("b") }, true, "a b"},
+		{func() bool { return c.Contains("a b") }, true, "a b c a b"},
+		{func() bool { c.Add("d", "a", "", "e f"); return true }, true, "a b c a b d"},
+		{func() bool { c.Remove("a b", "x"); return true }, true, "a b c d"},
+		{func() bool { return c.Toggle("b") }, false, "a c d"},
+		{func() bool { return c.Toggle("b") }, true, "a c d b"},
+		{func() bool { return c.Replace("a", "d") }, true, "d c b"},
+		{func() bool { return c.Replace("x", "y") }, false, "d c b"},
+		{func() bool { return c.Replace("c", "") }, false, "d c b"},
+		{func() bool { return c.Replace("c", "z") }, true, "d z b"},
+	}
+	for i, test := range tests {
+		if r := test.op(); r != test.result {
+			t.Errorf("Operation %d: expected result %t, got %t", i, test.result, r)
+		}
+		if i > 1 && AttrVal(n, "", "class") != test.expect {
+			t.Errorf("Operation %d: expected class attribute \"%s\", got \"%s\"", i, test.expect, AttrVal(n, "", "class"))
+		}
+	}
+}
+
+func TestClassWhitespace(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader("<p id=a class=\"x\ty\">1</p><p id=b class=\"x\ny\">2</p><p id=c class=\"x y\">3</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, "ElementsByClassName", ElementsByClassName(doc, "x", "y"), "a", "b")
+	expectIDs(t, "Class", FindAll(doc, Class("y")), "a", "b")
+	found, err := QueryAll(doc, ".x.y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, "QueryAll", found, "a", "b")
+}
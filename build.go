@@ -0,0 +1,195 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+)
+
+//NodeOption configures an element created by El.
+type NodeOption func(*html.Node)
+
+//Returns a new element with the given tag name, configured by opts in order, e.g.
+//	El("a", WithAttr("href", u), Text("more"))
+//Tag and attribute names are treated like the HTML parser does: HTML names are lowercased and DataAtom is set accordingly,
+//custom elements get the zero atom. The svg and math elements are put in the SVG and MathML namespace and so are their
+//descendant elements that do not have a namespace, except for the contents of integration points like foreignObject.
+//Names of SVG elements and attributes get their camel case form, e.g. "viewBox", and prefixed attributes of foreign
+//elements like "xlink:href" are put in their namespace.
+func El(tag string, opts ...NodeOption) *html.Node {
+	n := &html.Node{Type: html.ElementNode, Data: strings.ToLower(tag)}
+	switch n.Data {
+	case "svg", "math":
+		n.Namespace = n.Data
+	}
+	n.DataAtom = atom.Lookup([]byte(n.Data))
+	for _, o := range opts {
+		o(n)
+	}
+	adjustForeign(n)
+	return n
+}
+
+//Returns an option that sets the attribute key to val.
+func WithAttr(key, val string) NodeOption {
+	return func(n *html.Node) {
+		key := strings.ToLower(key)
+		if n.Namespace != "" {
+			ns, k := foreignAttrName(n.Namespace, key)
+			SetAttr(n, ns, k, val)
+			return
+		}
+		SetAttr(n, "", key, val)
+	}
+}
+
+//Returns an option that adds the given class names, see ClassList.
+func WithClass(name ...string) NodeOption {
+	return func(n *html.Node) {
+		Classes(n).Add(name...)
+	}
+}
+
+//Returns an option that appends the given nodes as children, nodes are detached first.
+//Nodes that would become their own ancestors are skipped.
+func WithChildren(nodes ...*html.Node) NodeOption {
+	return func(n *html.Node) {
+		for _, c := range nodes {
+			if Append(n, c) == nil {
+				adoptNamespace(n, c)
+			}
+		}
+	}
+}
+
+//Returns an option that appends a text node with the given text.
+func Text(s string) NodeOption {
+	return WithChildren(TextNode(s))
+}
+
+//Returns a new text node.
+func TextNode(s string) *html.Node {
+	return &html.Node{Type: html.TextNode, Data: s}
+}
+
+//Returns a new comment node.
+func CommentNode(s string) *html.Node {
+	return &html.Node{Type: html.CommentNode, Data: s}
+}
+
+//Parses s as the content of the element ctx, as it happens when the DOM's innerHTML is set, and returns the top level nodes.
+//A list item for example needs a list as context: ParseFragment(El("ul"), "<li>1<li>2").
+//The body element is used as context if ctx is nil. ctx is not modified, its DataAtom is derived from its Data.
+func ParseFragment(ctx *html.Node, s string) ([]*html.Node, error) {
+	if ctx == nil {
+		ctx = El("body")
+	} else if ctx.Type == html.ElementNode && ctx.DataAtom != atom.Lookup([]byte(ctx.Data)) {
+		c := *ctx
+		c.DataAtom = atom.Lookup([]byte(c.Data))
+		ctx = &c
+	}
+	return html.ParseFragment(strings.NewReader(s), ctx)
+}
+
+//Puts the descendants of parent that were created without namespace in the namespace of parent, see El.
+func adoptNamespace(parent, n *html.Node) {
+	if parent.Namespace == "" || integrationPoint(parent) || n.Type != html.ElementNode || n.Namespace != "" {
+		return
+	}
+	Walk(n, func(d *html.Node) WalkAction {
+		if d.Type != html.ElementNode || d.Namespace != "" {
+			return SkipChildren
+		}
+		d.Namespace = parent.Namespace
+		adjustForeign(d)
+		if integrationPoint(d) {
+			return SkipChildren
+		}
+		return Continue
+	}, nil)
+}
+
+//Reports whether n is an element of a foreign namespace whose children are HTML elements.
+func integrationPoint(n *html.Node) bool {
+	switch n.Namespace {
+	case "svg":
+		return n.Data == "foreignObject" || n.Data == "desc" || n.Data == "title"
+	case "math":
+		switch n.Data {
+		case "mi", "mo", "mn", "ms", "mtext":
+			return true
+		case "annotation-xml":
+			enc, _ := attrValFold(n, "encoding")
+			return strings.EqualFold(enc, "text/html") || strings.EqualFold(enc, "application/xhtml+xml")
+		}
+	}
+	return false
+}
+
+//Adjusts the names of a foreign element and its attributes like the HTML parser does.
+func adjustForeign(n *html.Node) {
+	switch n.Namespace {
+	case "":
+		return
+	case "svg":
+		if s, ok := svgTagNames[n.Data]; ok {
+			n.Data = s
+			n.DataAtom = atom.Lookup([]byte(s))
+		}
+	}
+	for i, a := range n.Attr {
+		if a.Namespace == "" {
+			n.Attr[i].Namespace, n.Attr[i].Key = foreignAttrName(n.Namespace, a.Key)
+		}
+	}
+}
+
+//Returns the namespace and key of the attribute key of an element in the foreign namespace ns.
+func foreignAttrName(ns, key string) (string, string) {
+	for _, prefix := range []string{"xlink", "xml", "xmlns"} {
+		if k, ok := strings.CutPrefix(key, prefix+":"); ok {
+			return prefix, k
+		}
+	}
+	switch ns {
+	case "svg":
+		if s, ok := svgAttrNames[key]; ok {
+			return "", s
+		}
+	case "math":
+		if key == "definitionurl" {
+			return "", "definitionURL"
+		}
+	}
+	return "", key
+}
+
+//Lowercased names of SVG elements and attributes that the HTML parser converts to camel case.
+var svgTagNames, svgAttrNames = camelCaseNames(
+	"altGlyph", "altGlyphDef", "altGlyphItem", "animateColor", "animateMotion", "animateTransform", "clipPath",
+	"feBlend", "feColorMatrix", "feComponentTransfer", "feComposite", "feConvolveMatrix", "feDiffuseLighting",
+	"feDisplacementMap", "feDistantLight", "feDropShadow", "feFlood", "feFuncA", "feFuncB", "feFuncG", "feFuncR",
+	"feGaussianBlur", "feImage", "feMerge", "feMergeNode", "feMorphology", "feOffset", "fePointLight",
+	"feSpecularLighting", "feSpotLight", "feTile", "feTurbulence", "foreignObject", "glyphRef", "linearGradient",
+	"radialGradient", "textPath",
+), camelCaseNames(
+	"attributeName", "attributeType", "baseFrequency", "baseProfile", "calcMode", "clipPathUnits", "diffuseConstant",
+	"edgeMode", "filterUnits", "glyphRef", "gradientTransform", "gradientUnits", "kernelMatrix", "kernelUnitLength",
+	"keyPoints", "keySplines", "keyTimes", "lengthAdjust", "limitingConeAngle", "markerHeight", "markerUnits",
+	"markerWidth", "maskContentUnits", "maskUnits", "numOctaves", "pathLength", "patternContentUnits",
+	"patternTransform", "patternUnits", "pointsAtX", "pointsAtY", "pointsAtZ", "preserveAlpha", "preserveAspectRatio",
+	"primitiveUnits", "refX", "refY", "repeatCount", "repeatDur", "requiredExtensions", "requiredFeatures",
+	"specularConstant", "specularExponent", "spreadMethod", "startOffset", "stdDeviation", "stitchTiles",
+	"surfaceScale", "systemLanguage", "tableValues", "targetX", "targetY", "textLength", "viewBox", "viewTarget",
+	"xChannelSelector", "yChannelSelector", "zoomAndPan",
+)
+
+func camelCaseNames(names ...string) map[string]string {
+	m := make(map[string]string, len(names))
+	for _, s := range names {
+		m[strings.ToLower(s)] = s
+	}
+	return m
+}
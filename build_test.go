@@ -0,0 +1,110 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestEl(t *testing.T) {
+	a := El("A", WithAttr("HREF", "/more"), WithClass("x", "y"), Text("more"))
+	if a.Data != "a" || a.DataAtom != atom.A || AttrVal(a, "", "href") != "/more" || AttrVal(a, "", "class") != "x y" || TextContent(a) != "more" {
+		t.Errorf("Unexpected element %v", a)
+	}
+	custom := El("my-widget")
+	if custom.DataAtom != 0 || custom.Data != "my-widget" {
+		t.Errorf("Custom elements must have the zero atom, got %v", custom.DataAtom)
+	}
+
+	svg := El("svg", WithAttr("viewBox", "0 0 10 10"), WithChildren(
+		El("linearGradient", WithAttr("gradientUnits", "userSpaceOnUse")),
+		El("use", WithAttr("xlink:href", "#icon")),
+		El("foreignObject", WithChildren(El("p", Text("html")))),
+		El("g", WithChildren(El("circle"))),
+	))
+	expect := `<svg viewBox="0 0 10 10"><linearGradient gradientUnits="userSpaceOnUse"></linearGradient><use xlink:href="#icon"></use>` +
+		`<foreignObject><p>html</p></foreignObject><g><circle></circle></g></svg>`
+	var b strings.Builder
+	if err := html.Render(&b, svg); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != expect {
+		t.Errorf("Expected %s, got %s", expect, b.String())
+	}
+
+	//the builder must produce the same tree as the parser
+	doc, err := html.Parse(strings.NewReader("<body>" + expect))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := FirstElementByTag(doc, atom.Svg)
+	built := collectNodes(svg)
+	for i, n := range collectNodes(parsed) {
+		if i >= len(built) {
+			t.Fatal("The built tree has fewer nodes than the parsed one")
+		}
+		if b := built[i]; n.Namespace != b.Namespace || n.Data != b.Data || n.DataAtom != b.DataAtom || len(n.Attr) != len(b.Attr) || len(n.Attr) > 0 && n.Attr[0] != b.Attr[0] {
+			t.Errorf("Node %d: parsed %q %q %v %v, built %q %q %v %v", i, n.Namespace, n.Data, n.DataAtom, n.Attr, b.Namespace, b.Data, b.DataAtom, b.Attr)
+		}
+	}
+
+	math := El("math", WithChildren(El("mi", WithChildren(El("b"))), El("mrow", WithAttr("definitionURL", "u"))))
+	if mi := math.FirstChild; mi.Namespace != "math" || mi.FirstChild.Namespace != "" || AttrVal(math.LastChild, "", "definitionURL") != "u" {
+		t.Error("Unexpected MathML tree")
+	}
+
+	//moving a node into its own subtree is refused
+	outer := El("div", WithChildren(El("span")))
+	El("p", WithChildren(outer))
+	WithChildren(outer)(outer.FirstChild)
+	if outer.FirstChild.FirstChild != nil {
+		t.Error("Children must skip nodes that would become their own ancestors")
+	}
+}
+
+func collectNodes(n *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for d := range Descendants(n) {
+		nodes = append(nodes, d)
+	}
+	return nodes
+}
+
+func TestParseFragment(t *testing.T) {
+	tests := []struct {
+		ctx    *html.Node
+		src    string
+		expect []string
+	}{
+		{El("ul"), "<li>1<li>2", []string{"li", "li"}},
+		{&html.Node{Type: html.ElementNode, Data: "tr"}, "<td>1<td>2", []string{"td", "td"}},
+		{nil, "<td>1</td><p>2", []string{"#text", "p"}},
+		{El("table"), "<tr><td>1", []string{"tbody"}},
+	}
+	for _, test := range tests {
+		nodes, err := ParseFragment(test.ctx, test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, n := range nodes {
+			if n.Type == html.TextNode {
+				names = append(names, "#text")
+			} else {
+				names = append(names, n.Data)
+			}
+			if n.Parent != nil {
+				t.Error("Fragment nodes must not have a parent")
+			}
+		}
+		if strings.Join(names, " ") != strings.Join(test.expect, " ") {
+			t.Errorf("%q: expected %v, got %v", test.src, test.expect, names)
+		}
+	}
+	if _, err := ParseFragment(TextNode("x"), "<p>"); err == nil {
+		t.Error("Expected an error for a text node context")
+	}
+}
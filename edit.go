@@ -0,0 +1,196 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"errors"
+	"golang.org/x/net/html"
+)
+
+//Errors returned by the tree editing functions.
+var (
+	ErrNilNode  = errors.New("node is nil")
+	ErrNoParent = errors.New("node has no parent")
+	ErrCycle    = errors.New("node would become its own ancestor")
+)
+
+//Removes n from its parent and returns it. Detach does nothing if n has no parent.
+func Detach(n *html.Node) *html.Node {
+	if n != nil && n.Parent != nil {
+		n.Parent.RemoveChild(n)
+	}
+	return n
+}
+
+//Removes n from its parent. Returns ErrNoParent if n has no parent.
+func Remove(n *html.Node) error {
+	if err := checkNodes(n); err != nil {
+		return err
+	}
+	if n.Parent == nil {
+		return ErrNoParent
+	}
+	n.Parent.RemoveChild(n)
+	return nil
+}
+
+//Appends n to the children of parent, n is detached first.
+//Returns ErrCycle if n is parent or one of its ancestors.
+func Append(parent, n *html.Node) error {
+	if err := checkInsert(parent, n); err != nil {
+		return err
+	}
+	parent.AppendChild(Detach(n))
+	return nil
+}
+
+//Inserts n as first child of parent, n is detached first.
+//Returns ErrCycle if n is parent or one of its ancestors.
+func Prepend(parent, n *html.Node) error {
+	if err := checkInsert(parent, n); err != nil {
+		return err
+	}
+	parent.InsertBefore(Detach(n), parent.FirstChild)
+	return nil
+}
+
+//Inserts n as previous sibling of ref, n is detached first. Inserting a node before itself does nothing.
+//Returns ErrNoParent if ref has no parent and ErrCycle if n is one of the ancestors of ref.
+func InsertBefore(ref, n *html.Node) error {
+	if err := checkSibling(ref, n); err != nil || n == ref {
+		return err
+	}
+	ref.Parent.InsertBefore(Detach(n), ref)
+	return nil
+}
+
+//Inserts n as next sibling of ref, n is detached first. Inserting a node after itself does nothing.
+//Returns ErrNoParent if ref has no parent and ErrCycle if n is one of the ancestors of ref.
+func InsertAfter(ref, n *html.Node) error {
+	if err := checkSibling(ref, n); err != nil || n == ref {
+		return err
+	}
+	Detach(n)
+	ref.Parent.InsertBefore(n, ref.NextSibling)
+	return nil
+}
+
+//Replaces old by n, n is detached first. Replacing a node by itself does nothing.
+//Returns ErrNoParent if old has no parent and ErrCycle if n is an ancestor of old.
+func ReplaceWith(old, n *html.Node) error {
+	if err := checkNodes(old, n); err != nil {
+		return err
+	}
+	if old == n {
+		return nil
+	}
+	if err := InsertBefore(old, n); err != nil {
+		return err
+	}
+	old.Parent.RemoveChild(old)
+	return nil
+}
+
+//Puts wrapper in the place of n and appends n to the children of wrapper, wrapper is detached first.
+//If n has no parent, n is only appended to wrapper. Returns ErrCycle if wrapper is n or one of its ancestors.
+func Wrap(n, wrapper *html.Node) error {
+	if err := checkInsert(n, wrapper); err != nil {
+		return err
+	}
+	Detach(wrapper)
+	if n.Parent != nil {
+		n.Parent.InsertBefore(wrapper, n)
+		n.Parent.RemoveChild(n)
+	}
+	wrapper.AppendChild(n)
+	return nil
+}
+
+//Replaces n by its children. Returns ErrNoParent if n has no parent.
+func Unwrap(n *html.Node) error {
+	if err := checkNodes(n); err != nil {
+		return err
+	}
+	if n.Parent == nil {
+		return ErrNoParent
+	}
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		n.RemoveChild(c)
+		n.Parent.InsertBefore(c, n)
+	}
+	n.Parent.RemoveChild(n)
+	return nil
+}
+
+//Removes all children of n.
+func Empty(n *html.Node) {
+	if n == nil {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = n.FirstChild {
+		n.RemoveChild(c)
+	}
+}
+
+//Appends all children of src to the children of dst, keeping their order. Moving the children of a node to itself does nothing.
+//Returns ErrCycle if dst is a descendant of src.
+func MoveChildren(dst, src *html.Node) error {
+	if err := checkNodes(dst, src); err != nil {
+		return err
+	}
+	if dst == src {
+		return nil
+	}
+	if isInclusiveAncestor(src, dst) {
+		return ErrCycle
+	}
+	for c := src.FirstChild; c != nil; c = src.FirstChild {
+		src.RemoveChild(c)
+		dst.AppendChild(c)
+	}
+	return nil
+}
+
+func checkNodes(nodes ...*html.Node) error {
+	for _, n := range nodes {
+		if n == nil {
+			return ErrNilNode
+		}
+	}
+	return nil
+}
+
+//Checks whether n can be inserted as a child of parent.
+func checkInsert(parent, n *html.Node) error {
+	if err := checkNodes(parent, n); err != nil {
+		return err
+	}
+	if isInclusiveAncestor(n, parent) {
+		return ErrCycle
+	}
+	return nil
+}
+
+//Checks whether n can be inserted as a sibling of ref.
+func checkSibling(ref, n *html.Node) error {
+	if err := checkNodes(ref, n); err != nil {
+		return err
+	}
+	if ref.Parent == nil {
+		return ErrNoParent
+	}
+	if isInclusiveAncestor(n, ref.Parent) {
+		return ErrCycle
+	}
+	return nil
+}
+
+//Reports whether a is n or one of its ancestors.
+func isInclusiveAncestor(a, n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if n == a {
+			return true
+		}
+	}
+	return false
+}
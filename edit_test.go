@@ -0,0 +1,108 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestTreeEditing(t *testing.T) {
+	const src = `<div id="root"><p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p></div>`
+	el := func(tag string) *html.Node {
+		return &html.Node{Type: html.ElementNode, DataAtom: atom.Lookup([]byte(tag)), Data: tag}
+	}
+	tests := []struct {
+		name   string
+		edit   func(id func(string) *html.Node) error
+		err    error
+		expect string
+	}{
+		{"Remove", func(id func(string) *html.Node) error {
+			return Remove(id("b"))
+		}, nil, `<p id="a">A</p><p id="c">C</p>`},
+		{"RemoveDetached", func(id func(string) *html.Node) error {
+			return Remove(Detach(id("b")))
+		}, ErrNoParent, `<p id="a">A</p><p id="c">C</p>`},
+		{"InsertBefore", func(id func(string) *html.Node) error {
+			return InsertBefore(id("a"), id("c"))
+		}, nil, `<p id="c">C</p><p id="a">A</p><p id="b">B <b id="bold">bold</b></p>`},
+		{"InsertAfter", func(id func(string) *html.Node) error {
+			return InsertAfter(id("c"), id("bold"))
+		}, nil, `<p id="a">A</p><p id="b">B </p><p id="c">C</p><b id="bold">bold</b>`},
+		{"InsertBeforeSelf", func(id func(string) *html.Node) error {
+			return InsertBefore(id("b"), id("b"))
+		}, nil, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"InsertAfterSelf", func(id func(string) *html.Node) error {
+			return InsertAfter(id("a"), id("a"))
+		}, nil, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"InsertIntoDescendant", func(id func(string) *html.Node) error {
+			return InsertBefore(id("bold"), id("b"))
+		}, ErrCycle, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"InsertNextToDetached", func(id func(string) *html.Node) error {
+			return InsertBefore(el("p"), el("hr"))
+		}, ErrNoParent, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"ReplaceWith", func(id func(string) *html.Node) error {
+			return ReplaceWith(id("a"), id("bold"))
+		}, nil, `<b id="bold">bold</b><p id="b">B </p><p id="c">C</p>`},
+		{"ReplaceWithAncestor", func(id func(string) *html.Node) error {
+			return ReplaceWith(id("bold"), id("b"))
+		}, ErrCycle, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"Wrap", func(id func(string) *html.Node) error {
+			return Wrap(id("a"), el("section"))
+		}, nil, `<section><p id="a">A</p></section><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"WrapInDescendant", func(id func(string) *html.Node) error {
+			return Wrap(id("b"), id("bold"))
+		}, nil, `<p id="a">A</p><b id="bold">bold<p id="b">B </p></b><p id="c">C</p>`},
+		{"WrapInAncestor", func(id func(string) *html.Node) error {
+			return Wrap(id("bold"), id("b"))
+		}, ErrCycle, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"Unwrap", func(id func(string) *html.Node) error {
+			return Unwrap(id("b"))
+		}, nil, `<p id="a">A</p>B <b id="bold">bold</b><p id="c">C</p>`},
+		{"Empty", func(id func(string) *html.Node) error {
+			Empty(id("b"))
+			Empty(nil)
+			return nil
+		}, nil, `<p id="a">A</p><p id="b"></p><p id="c">C</p>`},
+		{"MoveChildren", func(id func(string) *html.Node) error {
+			return MoveChildren(id("a"), id("b"))
+		}, nil, `<p id="a">AB <b id="bold">bold</b></p><p id="b"></p><p id="c">C</p>`},
+		{"MoveChildrenIntoDescendant", func(id func(string) *html.Node) error {
+			return MoveChildren(id("bold"), id("root"))
+		}, ErrCycle, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+		{"Append", func(id func(string) *html.Node) error {
+			return Append(id("c"), id("a"))
+		}, nil, `<p id="b">B <b id="bold">bold</b></p><p id="c">C<p id="a">A</p></p>`},
+		{"Prepend", func(id func(string) *html.Node) error {
+			return Prepend(id("root"), id("c"))
+		}, nil, `<p id="c">C</p><p id="a">A</p><p id="b">B <b id="bold">bold</b></p>`},
+		{"Nil", func(id func(string) *html.Node) error {
+			return Append(id("root"), nil)
+		}, ErrNilNode, `<p id="a">A</p><p id="b">B <b id="bold">bold</b></p><p id="c">C</p>`},
+	}
+	for _, test := range tests {
+		doc, err := html.Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		id := func(id string) *html.Node {
+			return ElementByID(doc, id)
+		}
+		root := id("root")
+		if err := test.edit(id); err != test.err {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+		var b strings.Builder
+		for c := root.FirstChild; c != nil; c = c.NextSibling {
+			if err := html.Render(&b, c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if b.String() != test.expect {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expect, b.String())
+		}
+	}
+}
@@ -0,0 +1,566 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+//ErrNotForm is returned by NewForm if the given node is not a form element.
+var ErrNotForm = errors.New("node is not a form element")
+
+//Encoding types of form submissions.
+const (
+	FormURLEncoded = "application/x-www-form-urlencoded"
+	FormMultipart  = "multipart/form-data"
+	FormTextPlain  = "text/plain"
+)
+
+//Form is the data model of a form element.
+//The fields of its controls hold the default values from the document and may be changed before the form is submitted.
+type Form struct {
+	Node     *html.Node
+	Method   string         //"get", "post" or "dialog"
+	Action   string         //value of the action attribute as found in the document
+	Enctype  string         //one of FormURLEncoded, FormMultipart and FormTextPlain
+	Controls []*FormControl //all controls owned by the form in tree order
+}
+
+//FormControl is an input, button, select or textarea element that belongs to a Form.
+type FormControl struct {
+	Node     *html.Node
+	Type     string //the input type, the button type, "select-one", "select-multiple" or "textarea"
+	Name     string
+	Value    string //current value, for checkboxes and radio buttons the value that is submitted when checked
+	Checked  bool   //checkedness of checkboxes and radio buttons
+	Disabled bool
+	Multiple bool          //true for select elements that allow multiple selected options
+	Options  []*FormOption //options of a select element
+}
+
+//FormOption is an option of a select element.
+type FormOption struct {
+	Node     *html.Node
+	Value    string
+	Label    string
+	Selected bool
+	Disabled bool
+}
+
+//FormSubmission is an encoded form submission.
+type FormSubmission struct {
+	Method      string   //"GET" or "POST"
+	URL         *url.URL //the resolved action URL, including the query for GET submissions
+	ContentType string   //content type of Body, empty for GET submissions
+	Body        []byte
+}
+
+//Creates the data model of form element n. Controls are associated with the form by their form attribute
+//or by being its descendant, the rest of the tree is searched for controls with a form attribute.
+//Returns ErrNotForm if n is not a form element.
+func NewForm(n *html.Node) (*Form, error) {
+	if !isHTMLElement(n) || n.DataAtom != atom.Form {
+		return nil, ErrNotForm
+	}
+	return newForm(n, indexForms(treeRoot(n))), nil
+}
+
+//Returns the data models of all form elements in the subtree of n. Returns nil if no forms were found.
+func Forms(n *html.Node) []*Form {
+	var forms []*Form
+	var index formIndex
+	for _, e := range ElementsByTag(n, atom.Form) {
+		if !isHTMLElement(e) {
+			continue
+		}
+		if index == nil {
+			index = indexForms(treeRoot(n))
+		}
+		forms = append(forms, newForm(e, index))
+	}
+	return forms
+}
+
+func newForm(n *html.Node, index formIndex) *Form {
+	f := &Form{
+		Node:    n,
+		Method:  formMethod(AttrVal(n, "", "method")),
+		Action:  AttrVal(n, "", "action"),
+		Enctype: formEnctype(AttrVal(n, "", "enctype")),
+	}
+	for _, e := range index[n] {
+		f.Controls = append(f.Controls, newFormControl(e))
+	}
+	return f
+}
+
+//formIndex holds the controls of each form element in tree order.
+type formIndex map[*html.Node][]*html.Node
+
+//Associates all controls in the tree of root with the forms that own them in a single pass. A control is owned by
+//the form its form attribute refers to, or by its closest form ancestor if it has no form attribute.
+//Controls in templates are not owned by any form.
+func indexForms(root *html.Node) formIndex {
+	type control struct {
+		node  *html.Node
+		owner *html.Node //the form ancestor
+		form  *string    //the value of the form attribute
+	}
+	ids := make(map[string]*html.Node)
+	var controls []control
+	var forms []*html.Node
+	templates := 0
+	Walk(root, func(e *html.Node) WalkAction {
+		if e.Type != html.ElementNode {
+			return Continue
+		}
+		if HasAttr(e, "", "id") {
+			if id := AttrVal(e, "", "id"); ids[id] == nil {
+				ids[id] = e
+			}
+		}
+		if !isHTMLElement(e) {
+			return Continue
+		}
+		switch e.DataAtom {
+		case atom.Template:
+			templates++
+		case atom.Form:
+			forms = append(forms, e)
+		case atom.Input, atom.Button, atom.Select, atom.Textarea:
+			if templates > 0 {
+				break
+			}
+			c := control{node: e}
+			if id, ok := attrValFold(e, "form"); ok {
+				c.form = &id
+			} else if len(forms) > 0 {
+				c.owner = forms[len(forms)-1]
+			}
+			controls = append(controls, c)
+		}
+		return Continue
+	}, func(e *html.Node) WalkAction {
+		if isHTMLElement(e) {
+			switch e.DataAtom {
+			case atom.Template:
+				templates--
+			case atom.Form:
+				forms = forms[:len(forms)-1]
+			}
+		}
+		return Continue
+	})
+
+	index := make(formIndex)
+	for _, c := range controls {
+		owner := c.owner
+		if c.form != nil {
+			owner = nil
+			if e := ids[*c.form]; *c.form != "" && e != nil && e.DataAtom == atom.Form {
+				owner = e
+			}
+		}
+		if owner != nil {
+			index[owner] = append(index[owner], c.node)
+		}
+	}
+	return index
+}
+
+func formMethod(v string) string {
+	switch v = strings.ToLower(strings.TrimSpace(v)); v {
+	case "post", "dialog":
+		return v
+	}
+	return "get"
+}
+
+func formEnctype(v string) string {
+	switch v = strings.ToLower(strings.TrimSpace(v)); v {
+	case FormMultipart, FormTextPlain:
+		return v
+	}
+	return FormURLEncoded
+}
+
+func newFormControl(e *html.Node) *FormControl {
+	c := &FormControl{
+		Node:     e,
+		Name:     AttrVal(e, "", "name"),
+		Disabled: matchDisabled(e),
+	}
+	switch e.DataAtom {
+	case atom.Input:
+		c.Type = strings.ToLower(AttrVal(e, "", "type"))
+		switch c.Type {
+		case "hidden", "search", "tel", "url", "email", "password", "date", "month", "week", "time",
+			"datetime-local", "number", "range", "color", "checkbox", "radio", "file", "submit", "image", "reset", "button":
+		default:
+			c.Type = "text"
+		}
+		c.Value = AttrVal(e, "", "value")
+		switch c.Type {
+		case "checkbox", "radio":
+			if !HasAttr(e, "", "value") {
+				c.Value = "on"
+			}
+			c.Checked = HasAttr(e, "", "checked")
+		case "text", "search", "tel", "password":
+			c.Value = strings.NewReplacer("\r", "", "\n", "").Replace(c.Value)
+		case "url", "email":
+			c.Value = strings.Trim(strings.NewReplacer("\r", "", "\n", "").Replace(c.Value), asciiWhitespace)
+		case "file":
+			c.Value = ""
+		}
+	case atom.Button:
+		c.Type = strings.ToLower(AttrVal(e, "", "type"))
+		if c.Type != "reset" && c.Type != "button" {
+			c.Type = "submit"
+		}
+		c.Value = AttrVal(e, "", "value")
+	case atom.Textarea:
+		c.Type = "textarea"
+		c.Value = TextContent(e)
+	case atom.Select:
+		c.Type = "select-one"
+		c.Multiple = HasAttr(e, "", "multiple")
+		if c.Multiple {
+			c.Type = "select-multiple"
+		}
+		c.Options = selectOptions(e, c.Multiple)
+	}
+	return c
+}
+
+//Returns the options of select element e with their selectedness as defined by the selectedness setting algorithm.
+func selectOptions(e *html.Node, multiple bool) []*FormOption {
+	var opts []*FormOption
+	var selected *FormOption
+	Walk(e, func(n *html.Node) WalkAction {
+		if n == e || !isHTMLElement(n) {
+			return Continue
+		}
+		switch n.DataAtom {
+		case atom.Optgroup:
+			return Continue
+		case atom.Option:
+			label := strings.Join(strings.Fields(TextContent(n)), " ")
+			o := &FormOption{
+				Node:     n,
+				Value:    label,
+				Label:    label,
+				Selected: HasAttr(n, "", "selected"),
+				Disabled: matchDisabled(n),
+			}
+			if v, ok := attrValFold(n, "value"); ok {
+				o.Value = v
+			}
+			if l, ok := attrValFold(n, "label"); ok && l != "" {
+				o.Label = l
+			}
+			if o.Selected {
+				selected = o
+			}
+			opts = append(opts, o)
+		}
+		return SkipChildren
+	}, nil)
+	if multiple || spanAttr(e, "size", 1, 1<<31-1) > 1 {
+		return opts
+	}
+	//a single-select list box has exactly one selected option, the last one marked as selected wins
+	for _, o := range opts {
+		o.Selected = o == selected
+	}
+	if selected == nil {
+		for _, o := range opts {
+			if !o.Disabled {
+				o.Selected = true
+				break
+			}
+		}
+	}
+	return opts
+}
+
+//Returns the first control with the given name. Returns nil if no such control exists.
+func (f *Form) Control(name string) *FormControl {
+	for _, c := range f.Controls {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+//Returns all controls that can submit the form, i.e. submit buttons and image buttons, in tree order.
+//The first one is the form's default button.
+func (f *Form) SubmitButtons() []*FormControl {
+	var buttons []*FormControl
+	for _, c := range f.Controls {
+		if c.isSubmitButton() {
+			buttons = append(buttons, c)
+		}
+	}
+	return buttons
+}
+
+func (c *FormControl) isSubmitButton() bool {
+	return c.Type == "submit" || c.Type == "image"
+}
+
+//Sets the value of the controls called name the way a user would: text controls get the value,
+//checkboxes and radio buttons with a matching value are checked, other radio buttons of the group are unchecked,
+//and the matching option of a select element is selected. Returns false if no control accepted the value.
+func (f *Form) Set(name, value string) bool {
+	done := false
+	for _, c := range f.Controls {
+		if c.Name != name {
+			continue
+		}
+		switch c.Type {
+		case "submit", "image", "reset", "button", "file":
+		case "radio":
+			c.Checked = c.Value == value
+			done = done || c.Checked
+		case "checkbox":
+			if c.Value == value {
+				c.Checked = true
+				done = true
+			}
+		case "select-one", "select-multiple":
+			var match *FormOption
+			for _, o := range c.Options {
+				if o.Value == value {
+					match = o
+					break
+				}
+			}
+			if match == nil {
+				continue
+			}
+			for _, o := range c.Options {
+				if o == match {
+					o.Selected = true
+				} else if c.Type == "select-one" {
+					o.Selected = false
+				}
+			}
+			done = true
+		default:
+			if !done {
+				c.Value = value
+				done = true
+			}
+		}
+	}
+	return done
+}
+
+//formEntry is an entry of a form data set, file is true if the entry stems from a file input.
+type formEntry struct {
+	name, value string
+	file        bool
+}
+
+//Returns the data a browser would submit if the form was submitted by submitter, which may be nil
+//for a submission without a button. The values of url.Values are in tree order.
+func (f *Form) Values(submitter *FormControl) url.Values {
+	v := make(url.Values)
+	for _, e := range f.entries(submitter) {
+		v.Add(e.name, e.value)
+	}
+	return v
+}
+
+//Constructs the entry list of the form data set.
+func (f *Form) entries(submitter *FormControl) []formEntry {
+	var entries []formEntry
+	for _, c := range f.Controls {
+		if c.Disabled || ParentByTag(c.Node, atom.Datalist) != nil {
+			continue
+		}
+		if (c.isSubmitButton() || c.Type == "reset" || c.Type == "button") && c != submitter {
+			continue
+		}
+		if (c.Type == "checkbox" || c.Type == "radio") && !c.Checked {
+			continue
+		}
+		if c.Type == "image" {
+			prefix := ""
+			if c.Name != "" {
+				prefix = c.Name + "."
+			}
+			entries = append(entries, formEntry{name: prefix + "x", value: "0"}, formEntry{name: prefix + "y", value: "0"})
+			continue
+		}
+		if c.Name == "" {
+			continue
+		}
+		switch c.Type {
+		case "select-one", "select-multiple":
+			for _, o := range c.Options {
+				if o.Selected && !o.Disabled {
+					entries = append(entries, formEntry{name: c.Name, value: o.Value})
+				}
+			}
+		case "hidden":
+			value := c.Value
+			if strings.EqualFold(c.Name, "_charset_") {
+				value = "UTF-8"
+			}
+			entries = append(entries, formEntry{name: c.Name, value: value})
+		case "file":
+			entries = append(entries, formEntry{name: c.Name, value: c.Value, file: true})
+		default:
+			entries = append(entries, formEntry{name: c.Name, value: c.Value})
+		}
+		if dirname := AttrVal(c.Node, "", "dirname"); dirname != "" && (c.Type == "text" || c.Type == "search" || c.Type == "textarea") {
+			entries = append(entries, formEntry{name: dirname, value: "ltr"})
+		}
+	}
+	return entries
+}
+
+//Encodes the form as it would be submitted by submitter, which may be nil for a submission without a button.
+//The formaction, formmethod and formenctype attributes of the submitter take precedence over those of the form.
+//pageURL is the URL of the document the form is part of, the action is resolved against the document's base URL
+//as returned by BaseURL and an empty action submits to pageURL. Only http and https action URLs and the get and post methods are supported.
+func (f *Form) Submit(pageURL *url.URL, submitter *FormControl) (*FormSubmission, error) {
+	action, method, enctype := f.Action, f.Method, f.Enctype
+	if submitter != nil {
+		if v, ok := attrValFold(submitter.Node, "formaction"); ok {
+			action = v
+		}
+		if v, ok := attrValFold(submitter.Node, "formmethod"); ok {
+			method = formMethod(v)
+		}
+		if v, ok := attrValFold(submitter.Node, "formenctype"); ok {
+			enctype = formEnctype(v)
+		}
+	}
+	if method == "dialog" {
+		return nil, errors.New("forms with method dialog are not submitted")
+	}
+
+	u, err := parseURLAttr(action)
+	if err != nil {
+		return nil, fmt.Errorf("invalid form action: %w", err)
+	}
+	if u.String() == "" && pageURL != nil {
+		u = pageURL.ResolveReference(u)
+	} else if base := BaseURL(f.Node, pageURL); base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported form action scheme %q", u.Scheme)
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	entries := f.entries(submitter)
+	for i := range entries {
+		entries[i].name = normalizeNewlines(entries[i].name)
+		entries[i].value = normalizeNewlines(entries[i].value)
+	}
+	s := &FormSubmission{URL: u}
+	if method == "get" {
+		s.Method = http.MethodGet
+		u.RawQuery = urlEncode(entries)
+		return s, nil
+	}
+
+	s.Method = http.MethodPost
+	s.ContentType = enctype
+	switch enctype {
+	case FormURLEncoded:
+		s.Body = []byte(urlEncode(entries))
+	case FormTextPlain:
+		var b strings.Builder
+		for _, e := range entries {
+			b.WriteString(e.name + "=" + e.value + "\r\n")
+		}
+		s.Body = []byte(b.String())
+	case FormMultipart:
+		var b bytes.Buffer
+		w := multipart.NewWriter(&b)
+		for _, e := range entries {
+			if e.file {
+				if _, err := w.CreateFormFile(e.name, e.value); err != nil {
+					return nil, err
+				}
+			} else if err := w.WriteField(e.name, e.value); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		s.ContentType = w.FormDataContentType()
+		s.Body = b.Bytes()
+	}
+	return s, nil
+}
+
+//Returns an http.Request that performs the submission.
+func (s *FormSubmission) Request() (*http.Request, error) {
+	req, err := http.NewRequest(s.Method, s.URL.String(), bytes.NewReader(s.Body))
+	if err != nil {
+		return nil, err
+	}
+	if s.ContentType != "" {
+		req.Header.Set("Content-Type", s.ContentType)
+	}
+	return req, nil
+}
+
+//Encodes entries as application/x-www-form-urlencoded, keeping their order. Line breaks are normalized to CR LF.
+func urlEncode(entries []formEntry) string {
+	var b strings.Builder
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		formURLEscape(&b, normalizeNewlines(e.name))
+		b.WriteByte('=')
+		formURLEscape(&b, normalizeNewlines(e.value))
+	}
+	return b.String()
+}
+
+//Writes s to b percent-encoded with the application/x-www-form-urlencoded byte set of the URL standard:
+//ASCII alphanumerics and *-._ are kept, spaces become plus signs and all other bytes are escaped.
+func formURLEscape(b *strings.Builder, s string) {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '*', c == '-', c == '.', c == '_':
+			b.WriteByte(c)
+		case c == ' ':
+			b.WriteByte('+')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		}
+	}
+}
+
+//Replaces every line break by CR LF, as required for form submissions.
+func normalizeNewlines(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return s
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
@@ -0,0 +1,209 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewForm(t *testing.T) {
+	const testDoc = "form.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewForm(ElementByID(root, "login"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Method != "post" || f.Enctype != FormURLEncoded || f.Action != "/login?old=1#frag" {
+		t.Errorf("Unexpected form attributes %q %q %q", f.Method, f.Enctype, f.Action)
+	}
+	if len(f.Controls) != 18 {
+		t.Errorf("Expected 18 controls, got %d", len(f.Controls))
+	}
+	if c := f.Control("outside"); c == nil || c.Value != "associated" {
+		t.Error("Controls with a form attribute must be associated with the form")
+	}
+	if f.Control("stray") != nil {
+		t.Error("Controls outside of the form must not be associated with it")
+	}
+	if c := f.Control("user"); c.Type != "text" || c.Value != "linebreak" {
+		t.Errorf("Unexpected text control %q %q", c.Type, c.Value)
+	}
+	if c := f.Control("remember"); c.Type != "checkbox" || c.Value != "on" || !c.Checked {
+		t.Error("Unexpected checkbox state")
+	}
+	if c := f.Control("in-fieldset"); !c.Disabled {
+		t.Error("Controls in a disabled fieldset must be disabled")
+	}
+	lang := f.Control("lang")
+	if lang.Type != "select-one" || len(lang.Options) != 3 || !lang.Options[1].Selected || lang.Options[0].Selected || !lang.Options[2].Disabled {
+		t.Error("Unexpected select state")
+	}
+	if lang.Options[0].Value != "en" || lang.Options[1].Label != "Deutsch" {
+		t.Error("Unexpected option values")
+	}
+	if c := f.Control("comment"); c.Value != "Hello\nWorld" {
+		t.Errorf("Unexpected textarea value %q", c.Value)
+	}
+	if b := f.SubmitButtons(); len(b) != 3 || b[0].Value != "login" || b[2].Type != "image" {
+		t.Error("Unexpected submit buttons")
+	}
+
+	if _, err := NewForm(FirstElementByTag(root, atom.Body)); err != ErrNotForm {
+		t.Errorf("Expected ErrNotForm, got %v", err)
+	}
+	if forms := Forms(root); len(forms) != 2 {
+		t.Errorf("Expected 2 forms, got %d", len(forms))
+	}
+}
+
+func TestFormValues(t *testing.T) {
+	const testDoc = "form.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, _ := NewForm(ElementByID(root, "login"))
+	login := f.SubmitButtons()[0]
+	expect := url.Values{
+		"token":    {"abc"},
+		"user":     {"linebreak"},
+		"pass":     {""},
+		"remember": {"on"},
+		"mode":     {"b"},
+		"lang":     {"de"},
+		"tags":     {"x"},
+		"comment":  {"Hello\nWorld"},
+		"action":   {"login"},
+		"outside":  {"associated"},
+	}
+	if v := f.Values(login); !reflect.DeepEqual(v, expect) {
+		t.Errorf("Expected %v, got %v", expect, v)
+	}
+	if v := f.Values(nil); v.Has("action") || v.Has("map.x") {
+		t.Error("Buttons must only be submitted if they are the submitter")
+	}
+	if v := f.Values(f.SubmitButtons()[2]); v.Get("map.x") != "0" || v.Get("map.y") != "0" {
+		t.Error("Image buttons must submit their coordinates")
+	}
+
+	if !f.Set("user", "alice") || !f.Set("newsletter", "yes") || !f.Set("mode", "a") || !f.Set("lang", "en") {
+		t.Fatal("Set did not accept a valid value")
+	}
+	if f.Set("lang", "xx") || f.Set("missing", "x") {
+		t.Error("Set must report values no control accepted")
+	}
+	v := f.Values(login)
+	if v.Get("user") != "alice" || v.Get("newsletter") != "yes" || v.Get("mode") != "a" || v.Get("lang") != "en" {
+		t.Errorf("Set values were not submitted: %v", v)
+	}
+}
+
+func TestFormSubmit(t *testing.T) {
+	const testDoc = "form.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://example.net/account/page.html")
+
+	f, _ := NewForm(ElementByID(root, "login"))
+	buttons := f.SubmitButtons()
+	s, err := f.Submit(base, buttons[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Method != "POST" || s.URL.String() != "https://example.net/login?old=1" || s.ContentType != FormURLEncoded {
+		t.Errorf("Unexpected submission %s %s %s", s.Method, s.URL, s.ContentType)
+	}
+	const body = "token=abc&user=linebreak&pass=&remember=on&mode=b&lang=de&tags=x&comment=Hello%0D%0AWorld&action=login&outside=associated"
+	if string(s.Body) != body {
+		t.Errorf("Expected body %q, got %q", body, s.Body)
+	}
+
+	s, err = f.Submit(base, buttons[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Method != "GET" || s.URL.Host != "other.example" || s.URL.Query().Get("action") != "alt" || s.Body != nil {
+		t.Errorf("Submitter attributes were not applied: %s %s", s.Method, s.URL)
+	}
+	req, err := s.Request()
+	if err != nil || req.Method != "GET" || req.URL.String() != s.URL.String() {
+		t.Error("Request does not match the submission")
+	}
+
+	upload, _ := NewForm(ElementByID(root, "upload"))
+	s, err = upload.Submit(base, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s.ContentType, "multipart/form-data; boundary=") || s.URL.String() != "https://example.net/account/upload" {
+		t.Errorf("Unexpected multipart submission %s %s", s.ContentType, s.URL)
+	}
+	if body := string(s.Body); !strings.Contains(body, `name="doc"; filename=""`) || !strings.Contains(body, `name="title"`) {
+		t.Errorf("Unexpected multipart body %q", body)
+	}
+
+	if _, err := f.Submit(nil, buttons[0]); err == nil {
+		t.Error("Expected an error for a relative action without base URL")
+	}
+}
+
+func TestFormSubmitBase(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<base href="https://other.example/app/"><form id="f" action="search"></form><form id="e"></form>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://example.net/page?q=1")
+
+	f, _ := NewForm(ElementByID(doc, "f"))
+	if s, err := f.Submit(page, nil); err != nil || s.URL.String() != "https://other.example/app/search" {
+		t.Errorf("Form action must be resolved against the base element, got %v %v", s, err)
+	}
+	e, _ := NewForm(ElementByID(doc, "e"))
+	if s, err := e.Submit(page, nil); err != nil || s.URL.String() != "https://example.net/page" {
+		t.Errorf("An empty action must submit to the page URL, got %v %v", s, err)
+	}
+}
+
+func TestFormsOwners(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<form id="a"><input name="x"><template><input name="t"></template></form>` +
+		`<input name="y" form="b"><form id="b"><input name="z" form="a"><select name="s"></select></form><input name="n" form="">`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	forms := Forms(doc)
+	if len(forms) != 2 {
+		t.Fatalf("Expected 2 forms, got %d", len(forms))
+	}
+	for i, expect := range [][]string{{"x", "z"}, {"y", "s"}} {
+		var names []string
+		for _, c := range forms[i].Controls {
+			names = append(names, c.Name)
+		}
+		if !reflect.DeepEqual(names, expect) {
+			t.Errorf("Form %d: expected controls %v, got %v", i, expect, names)
+		}
+	}
+}
+
+func TestURLEncode(t *testing.T) {
+	entries := []formEntry{{name: "a*b c", value: "x\ny\r\nz\r"}, {name: "~é", value: "1+1=2&_.-"}}
+	const expect = "a*b+c=x%0D%0Ay%0D%0Az%0D%0A&%7E%C3%A9=1%2B1%3D2%26_.-"
+	if s := urlEncode(entries); s != expect {
+		t.Errorf("Expected %q, got %q", expect, s)
+	}
+}
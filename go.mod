@@ -1,5 +1,5 @@
 module github.com/jwdev42/rottensoup
 
-go 1.20
+go 1.23
 
 require golang.org/x/net v0.33.0
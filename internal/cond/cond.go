@@ -9,22 +9,54 @@ import (
 	"strings"
 )
 
-//Returns a function that collects all nodes who have an attribute that matches key and where its value matches the regex val.
-func AttrValByRegex(nodes *[]*html.Node, namespace, key string, val *regexp.Regexp) func(n *html.Node) bool {
-	return func(node *html.Node) bool {
-		for _, a := range node.Attr {
+//Returns a function that adds every given node to nodes for which match returns true.
+//If first is true, search will stop after first match.
+func Match(nodes *[]*html.Node, first bool, match func(*html.Node) bool) func(*html.Node) bool {
+	if first {
+		return MatchN(nodes, 1, match)
+	}
+	return MatchN(nodes, -1, match)
+}
+
+//Returns a function that adds every given node to nodes for which match returns true.
+//Search will stop as soon as nodes holds limit elements, a negative limit means no limit.
+func MatchN(nodes *[]*html.Node, limit int, match func(*html.Node) bool) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		if limit >= 0 && len(*nodes) >= limit {
+			return false
+		}
+		if !match(n) {
+			return true
+		}
+		*nodes = append(*nodes, n)
+		return limit < 0 || len(*nodes) < limit
+	}
+}
+
+//Splits s at ASCII whitespace as defined by the HTML standard, e.g. the value of a class attribute.
+//Other whitespace like non-breaking spaces is part of the tokens. Returns nil if s contains no tokens.
+func Tokens(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\n' || r == '\f' || r == '\r'
+	})
+}
+
+/* --- Predicates --- */
+
+//Returns a function that reports whether a node has an attribute that matches namespace and key and whose value matches the regex val.
+func AttrValByRegex(namespace, key string, val *regexp.Regexp) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		for _, a := range n.Attr {
 			if a.Namespace == namespace && a.Key == key && val.MatchString(a.Val) {
-				*nodes = append(*nodes, node)
 				return true
 			}
 		}
-		return true
+		return false
 	}
 }
 
-//Returns a function that adds every given node to nodes if it contains all attributes specified in attr.
-//If first is true, search will stop after first match.
-func MatchAttrs(nodes *[]*html.Node, first bool, attr ...html.Attribute) func(*html.Node) bool {
+//Returns a function that reports whether a node contains all attributes specified in attr.
+func Attrs(attr ...html.Attribute) func(*html.Node) bool {
 	return func(n *html.Node) bool {
 		for _, a := range attr {
 			found := false
@@ -35,22 +67,19 @@ func MatchAttrs(nodes *[]*html.Node, first bool, attr ...html.Attribute) func(*h
 				}
 			}
 			if !found {
-				return true
+				return false
 			}
 		}
-		*nodes = append(*nodes, n)
-		if first {
-			return false
-		}
 		return true
 	}
 }
 
-func MatchClassNames(nodes *[]*html.Node, first bool, name ...string) func(*html.Node) bool {
+//Returns a function that reports whether a node is a member of all given classes.
+func ClassNames(name ...string) func(*html.Node) bool {
 	return func(n *html.Node) bool {
 		for _, a := range n.Attr {
 			if a.Namespace == "" && a.Key == "class" {
-				attrNames := strings.Split(a.Val, " ")
+				attrNames := Tokens(a.Val)
 				for _, searchName := range name {
 					found := false
 					for _, attrName := range attrNames {
@@ -60,51 +89,31 @@ func MatchClassNames(nodes *[]*html.Node, first bool, name ...string) func(*html
 						}
 					}
 					if !found {
-						return true
+						return false
 					}
 				}
-				*nodes = append(*nodes, n)
-				if first {
-					return false
-				}
-				break
+				return true
 			}
 		}
-		return true
+		return false
 	}
 }
 
-func MatchTag(nodes *[]*html.Node, first bool, tag ...atom.Atom) func(*html.Node) bool {
-	return func(node *html.Node) bool {
+//Returns a function that reports whether a node matches at least one of the given tags.
+func Tag(tag ...atom.Atom) func(*html.Node) bool {
+	return func(n *html.Node) bool {
 		for _, t := range tag {
-			if node.DataAtom == t {
-				*nodes = append(*nodes, node)
-				if first {
-					return false
-				}
+			if n.DataAtom == t {
 				return true
 			}
 		}
-		return true
+		return false
 	}
 }
 
-/* --- Filters --- */
-
-func TagFilter(tag atom.Atom, f func(*html.Node) bool) func(*html.Node) bool {
+//Returns a function that reports whether a node is of NodeType t.
+func Type(t html.NodeType) func(*html.Node) bool {
 	return func(n *html.Node) bool {
-		if n.DataAtom != tag {
-			return true
-		}
-		return f(n)
-	}
-}
-
-func TypeFilter(t html.NodeType, f func(*html.Node) bool) func(*html.Node) bool {
-	return func(n *html.Node) bool {
-		if n.Type != t {
-			return true
-		}
-		return f(n)
+		return n.Type == t
 	}
 }
@@ -6,49 +6,156 @@ import (
 	"golang.org/x/net/html"
 )
 
-//Perform depth-first search on child nodes of n.
-func DFS(n *html.Node, pre, post func(*html.Node) bool) bool {
-	if pre != nil && !pre(n) {
-		return false
-	}
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if !DFS(c, pre, post) {
+//Action tells Walk how to proceed after a node has been visited.
+type Action int
+
+const (
+	Continue     Action = iota //proceed normally
+	SkipChildren               //do not descend into the children of the current node
+	Stop                       //end the walk immediately
+)
+
+//Iterative depth-first traversal core that follows the parent and sibling pointers of the tree, so that
+//its stack usage does not depend on the depth or width of the tree.
+//Children of nodes that are maxDepth levels below root are not visited, a negative maxDepth means no limit.
+func walk(root *html.Node, maxDepth int, pre, post func(*html.Node, int) Action) bool {
+	n, depth := root, 0
+	for {
+		act := Continue
+		if pre != nil {
+			act = pre(n, depth)
+		}
+		if act == Stop {
 			return false
 		}
+		if act != SkipChildren && n.FirstChild != nil && (maxDepth < 0 || depth < maxDepth) {
+			n = n.FirstChild
+			depth++
+			continue
+		}
+		//leave n and every ancestor whose last child n is
+		for {
+			if post != nil && post(n, depth) == Stop {
+				return false
+			}
+			if n == root {
+				return true
+			}
+			if n.NextSibling != nil {
+				n = n.NextSibling
+				break
+			}
+			n = n.Parent
+			depth--
+		}
 	}
-	if post != nil && !post(n) {
-		return false
+}
+
+func boolAction(f func(*html.Node) bool) func(*html.Node, int) Action {
+	if f == nil {
+		return nil
+	}
+	return func(n *html.Node, _ int) Action {
+		if f(n) {
+			return Continue
+		}
+		return Stop
 	}
-	return true
 }
 
-func Siblings(n *html.Node, reverse bool, pre, post func(*html.Node) bool) {
-	if reverse {
-		siblings(n.PrevSibling, reverse, pre, post)
-	} else {
-		siblings(n.NextSibling, reverse, pre, post)
+func boolDepthAction(f func(*html.Node, int) bool) func(*html.Node, int) Action {
+	if f == nil {
+		return nil
+	}
+	return func(n *html.Node, depth int) Action {
+		if f(n, depth) {
+			return Continue
+		}
+		return Stop
 	}
 }
 
-func siblings(n *html.Node, reverse bool, pre, post func(*html.Node) bool) {
-	if n == nil {
-		return
+//Perform depth-first search on child nodes of n.
+func DFS(n *html.Node, pre, post func(*html.Node) bool) bool {
+	return walk(n, -1, boolAction(pre), boolAction(post))
+}
+
+//Perform depth-first search on n and its descendants, passing the depth of each node relative to n to pre and post.
+//Nodes that are more than maxDepth levels below n are not visited, a negative maxDepth means no limit.
+func DFSDepth(n *html.Node, maxDepth int, pre, post func(*html.Node, int) bool) bool {
+	return walk(n, maxDepth, boolDepthAction(pre), boolDepthAction(post))
+}
+
+//Perform breadth-first search on n and its descendants, visiting the tree level by level.
+//The depth of each node relative to n is passed to f, search stops if f returns false.
+//Nodes that are more than maxDepth levels below n are not visited, a negative maxDepth means no limit.
+func BFS(n *html.Node, maxDepth int, f func(*html.Node, int) bool) bool {
+	level := []*html.Node{n}
+	var next []*html.Node
+	for depth := 0; len(level) > 0; depth++ {
+		for _, node := range level {
+			if !f(node, depth) {
+				return false
+			}
+			if maxDepth >= 0 && depth >= maxDepth {
+				continue
+			}
+			for c := node.FirstChild; c != nil; c = c.NextSibling {
+				next = append(next, c)
+			}
+		}
+		level, next = next, level[:0]
 	}
+	return true
+}
 
-	if pre != nil && !pre(n) {
-		return
+//Walk n and its descendants depth-first. pre is called before and post after the children of a node are visited,
+//post is also called for nodes whose children were skipped. SkipChildren returned by post has the same effect as Continue.
+//Returns false if the walk was stopped.
+func Walk(n *html.Node, pre, post func(*html.Node) Action) bool {
+	var depthPre, depthPost func(*html.Node, int) Action
+	if pre != nil {
+		depthPre = func(n *html.Node, _ int) Action {
+			return pre(n)
+		}
 	}
+	if post != nil {
+		depthPost = func(n *html.Node, _ int) Action {
+			return post(n)
+		}
+	}
+	return walk(n, -1, depthPre, depthPost)
+}
 
-	var next *html.Node
+//Visit the siblings that follow n, or precede n if reverse is true, starting with the closest one.
+//pre is called for each sibling, afterwards post is called for each sibling in reverse order.
+//As with DFS, returning false from either callback stops the whole visit, no post calls happen after pre returned false.
+//Returns false if the visit was stopped.
+func Siblings(n *html.Node, reverse bool, pre, post func(*html.Node) bool) bool {
+	forth := func(n *html.Node) *html.Node {
+		return n.NextSibling
+	}
+	back := func(n *html.Node) *html.Node {
+		return n.PrevSibling
+	}
 	if reverse {
-		next = n.PrevSibling
-	} else {
-		next = n.NextSibling
+		forth, back = back, forth
 	}
 
-	siblings(next, reverse, pre, post)
-
-	if post != nil {
-		post(n)
+	last := n
+	for s := forth(n); s != nil; s = forth(s) {
+		if pre != nil && !pre(s) {
+			return false
+		}
+		last = s
 	}
+	if post == nil {
+		return true
+	}
+	for s := last; s != n; s = back(s) {
+		if !post(s) {
+			return false
+		}
+	}
+	return true
 }
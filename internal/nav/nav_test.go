@@ -0,0 +1,278 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package nav
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"testing"
+)
+
+const hugeTreeSize = 100000
+
+//Recursive depth-first search as implemented before the traversal core became iterative, used as reference.
+func recursiveDFS(n *html.Node, pre, post func(*html.Node) bool) bool {
+	if pre != nil && !pre(n) {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !recursiveDFS(c, pre, post) {
+			return false
+		}
+	}
+	if post != nil && !post(n) {
+		return false
+	}
+	return true
+}
+
+func newDiv() *html.Node {
+	return &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
+}
+
+//Returns a chain of size nested elements.
+func deepTree(size int) *html.Node {
+	root := newDiv()
+	n := root
+	for i := 1; i < size; i++ {
+		c := newDiv()
+		n.AppendChild(c)
+		n = c
+	}
+	return root
+}
+
+//Returns an element with size-1 children.
+func wideTree(size int) *html.Node {
+	root := newDiv()
+	for i := 1; i < size; i++ {
+		root.AppendChild(newDiv())
+	}
+	return root
+}
+
+//Returns a tree where every element has up to fanout children.
+func balancedTree(size, fanout int) *html.Node {
+	nodes := []*html.Node{newDiv()}
+	for i := 1; i < size; i++ {
+		c := newDiv()
+		nodes[(i-1)/fanout].AppendChild(c)
+		nodes = append(nodes, c)
+	}
+	return nodes[0]
+}
+
+func TestDFSOrder(t *testing.T) {
+	root := balancedTree(1000, 3)
+	var expectPre, expectPost, pre, post []*html.Node
+	recursiveDFS(root, func(n *html.Node) bool {
+		expectPre = append(expectPre, n)
+		return true
+	}, func(n *html.Node) bool {
+		expectPost = append(expectPost, n)
+		return true
+	})
+	if !DFS(root, func(n *html.Node) bool {
+		pre = append(pre, n)
+		return true
+	}, func(n *html.Node) bool {
+		post = append(post, n)
+		return true
+	}) {
+		t.Error("DFS reported a stop although no callback returned false")
+	}
+	if len(pre) != len(expectPre) || len(post) != len(expectPost) {
+		t.Fatalf("Expected %d visited nodes, got %d pre and %d post visits", len(expectPre), len(pre), len(post))
+	}
+	for i := range pre {
+		if pre[i] != expectPre[i] || post[i] != expectPost[i] {
+			t.Fatalf("Traversal order differs from the recursive implementation at node %d", i)
+		}
+	}
+
+	count := 0
+	if DFS(root.FirstChild, nil, func(*html.Node) bool {
+		count++
+		return count < 10
+	}) {
+		t.Error("DFS should return false when stopped")
+	}
+	if count != 10 {
+		t.Errorf("Expected 10 post visits before stopping, got %d", count)
+	}
+
+	subtree, count := 0, 0
+	recursiveDFS(root.FirstChild, func(*html.Node) bool {
+		subtree++
+		return true
+	}, nil)
+	DFS(root.FirstChild, func(*html.Node) bool {
+		count++
+		return true
+	}, nil)
+	if count != subtree {
+		t.Errorf("DFS must visit exactly the subtree it was started on: expected %d visits, got %d", subtree, count)
+	}
+}
+
+//Returns the children of n.
+func children(n *html.Node) []*html.Node {
+	var c []*html.Node
+	for n := n.FirstChild; n != nil; n = n.NextSibling {
+		c = append(c, n)
+	}
+	return c
+}
+
+func TestHugeTrees(t *testing.T) {
+	for name, root := range map[string]*html.Node{
+		"deep": deepTree(hugeTreeSize),
+		"wide": wideTree(hugeTreeSize),
+	} {
+		pre, post := 0, 0
+		maxDepth := 0
+		DFSDepth(root, -1, func(n *html.Node, depth int) bool {
+			pre++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+			return true
+		}, func(*html.Node, int) bool {
+			post++
+			return true
+		})
+		if pre != hugeTreeSize || post != hugeTreeSize {
+			t.Errorf("%s: expected %d visits, got %d pre and %d post visits", name, hugeTreeSize, pre, post)
+		}
+		if name == "deep" && maxDepth != hugeTreeSize-1 || name == "wide" && maxDepth != 1 {
+			t.Errorf("%s: unexpected maximum depth %d", name, maxDepth)
+		}
+
+		skipped := 0
+		Walk(root, func(n *html.Node) Action {
+			skipped++
+			if n.Parent == root {
+				return SkipChildren
+			}
+			return Continue
+		}, nil)
+		if expect := 1 + len(children(root)); skipped != expect {
+			t.Errorf("%s: expected %d visits with skipped subtrees, got %d", name, expect, skipped)
+		}
+	}
+
+	root := wideTree(hugeTreeSize)
+	count := 0
+	Siblings(root.FirstChild, false, func(*html.Node) bool {
+		count++
+		return true
+	}, nil)
+	if count != hugeTreeSize-2 {
+		t.Errorf("Expected %d following siblings, got %d", hugeTreeSize-2, count)
+	}
+	count = 0
+	Siblings(root.LastChild, true, nil, func(*html.Node) bool {
+		count++
+		return true
+	})
+	if count != hugeTreeSize-2 {
+		t.Errorf("Expected %d preceding siblings, got %d", hugeTreeSize-2, count)
+	}
+}
+
+func TestSiblings(t *testing.T) {
+	root := wideTree(10)
+	start := root.FirstChild.NextSibling
+	var pre, post []*html.Node
+	if !Siblings(start, false, func(n *html.Node) bool {
+		pre = append(pre, n)
+		return true
+	}, func(n *html.Node) bool {
+		post = append(post, n)
+		return true
+	}) {
+		t.Error("Siblings reported a stop although no callback returned false")
+	}
+	if len(pre) != 7 || len(post) != 7 || pre[0] != start.NextSibling || post[0] != root.LastChild || post[6] != pre[0] {
+		t.Fatal("Unexpected visiting order")
+	}
+
+	pre, post = pre[:0], post[:0]
+	if Siblings(start, false, func(n *html.Node) bool {
+		pre = append(pre, n)
+		return len(pre) < 3
+	}, func(n *html.Node) bool {
+		post = append(post, n)
+		return true
+	}) {
+		t.Error("Siblings should return false when stopped by pre")
+	}
+	if len(pre) != 3 || len(post) != 0 {
+		t.Errorf("Expected 3 pre and no post visits, got %d and %d", len(pre), len(post))
+	}
+
+	post = post[:0]
+	if Siblings(root.LastChild, true, nil, func(n *html.Node) bool {
+		post = append(post, n)
+		return len(post) < 2
+	}) {
+		t.Error("Siblings should return false when stopped by post")
+	}
+	if len(post) != 2 || post[0] != root.FirstChild {
+		t.Error("post must stop the visit when it returns false")
+	}
+}
+
+func TestBFS(t *testing.T) {
+	root := balancedTree(40, 3)
+	prev := 0
+	count := 0
+	BFS(root, -1, func(n *html.Node, depth int) bool {
+		if depth < prev {
+			t.Fatal("BFS visited a shallower node after a deeper one")
+		}
+		prev = depth
+		count++
+		return true
+	})
+	if count != 40 {
+		t.Errorf("Expected 40 visits, got %d", count)
+	}
+	count = 0
+	BFS(root, 1, func(*html.Node, int) bool {
+		count++
+		return true
+	})
+	if count != 4 {
+		t.Errorf("Expected 4 visits with a maximum depth of 1, got %d", count)
+	}
+}
+
+func benchmarkDFS(b *testing.B, root *html.Node, dfs func(*html.Node, func(*html.Node) bool, func(*html.Node) bool) bool) {
+	accept := func(*html.Node) bool {
+		return true
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dfs(root, accept, accept)
+	}
+}
+
+func BenchmarkDFS(b *testing.B) {
+	trees := []struct {
+		name string
+		root *html.Node
+	}{
+		{"deep", deepTree(hugeTreeSize)},
+		{"wide", wideTree(hugeTreeSize)},
+		{"balanced", balancedTree(hugeTreeSize, 8)},
+	}
+	for _, tree := range trees {
+		b.Run("iterative/"+tree.name, func(b *testing.B) {
+			benchmarkDFS(b, tree.root, DFS)
+		})
+		b.Run("recursive/"+tree.name, func(b *testing.B) {
+			benchmarkDFS(b, tree.root, recursiveDFS)
+		})
+	}
+}
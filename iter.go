@@ -0,0 +1,68 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/nav"
+	"golang.org/x/net/html"
+	"iter"
+)
+
+//Returns an iterator over all descendants of n in depth-first order, n itself is not included.
+//Stopping the iteration stops the traversal.
+func Descendants(n *html.Node) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if !nav.DFS(c, yield, nil) {
+				return
+			}
+		}
+	}
+}
+
+//Returns an iterator over the ancestors of n, starting with its parent and ending with the root of the tree.
+func Ancestors(n *html.Node) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		for p := n.Parent; p != nil; p = p.Parent {
+			if !yield(p) {
+				return
+			}
+		}
+	}
+}
+
+//Returns an iterator over the direct children of n.
+func Children(n *html.Node) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+//Returns an iterator over all siblings that follow n, starting with the closest one.
+func FollowingSiblings(n *html.Node) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		nav.Siblings(n, false, yield, nil)
+	}
+}
+
+//Returns an iterator over all siblings that precede n, starting with the closest one.
+func PrecedingSiblings(n *html.Node) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		nav.Siblings(n, true, yield, nil)
+	}
+}
+
+//Returns an iterator that yields only the element nodes of seq.
+func ElementsOnly(seq iter.Seq[*html.Node]) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		for n := range seq {
+			if n.Type == html.ElementNode && !yield(n) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"testing"
+)
+
+func collectIDs(nodes []*html.Node) []string {
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, AttrVal(n, "", "id"))
+	}
+	return ids
+}
+
+func expectIDs(t *testing.T, name string, nodes []*html.Node, expect ...string) {
+	t.Helper()
+	ids := collectIDs(nodes)
+	if len(ids) != len(expect) {
+		t.Errorf("%s: expected %v, got %v", name, expect, ids)
+		return
+	}
+	for i := range ids {
+		if ids[i] != expect[i] {
+			t.Errorf("%s: expected %v, got %v", name, expect, ids)
+			return
+		}
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	const testDoc = "test.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := FindAll(root, Not(Type(html.DocumentNode)))
+	i := 0
+	for n := range Descendants(root) {
+		if i >= len(all) || n != all[i] {
+			t.Fatalf("Descendants diverges from depth-first order at node %d", i)
+		}
+		i++
+	}
+	if i != len(all) {
+		t.Errorf("Expected %d descendants, got %d", len(all), i)
+	}
+
+	i = 0
+	for range Descendants(root) {
+		i++
+		if i == 3 {
+			break
+		}
+	}
+	if i != 3 {
+		t.Errorf("Expected iteration to stop after 3 nodes, got %d", i)
+	}
+
+	parent := ElementByID(root, "TestNextSiblingByTag")
+	var elems []*html.Node
+	for e := range ElementsOnly(Descendants(parent)) {
+		elems = append(elems, e)
+		if e.DataAtom == atom.Br {
+			break
+		}
+	}
+	expectIDs(t, "ElementsOnly(Descendants)", elems, "StartTestNextSiblingByTag", "pre1", "", "")
+}
+
+func TestAncestors(t *testing.T) {
+	const testDoc = "test.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	li := FirstElementByTag(root, atom.Li)
+	expect := []atom.Atom{atom.Ul, atom.Body, atom.Html, 0}
+	i := 0
+	for a := range Ancestors(li) {
+		if i >= len(expect) || a.DataAtom != expect[i] {
+			t.Fatalf("Unexpected ancestor %q at position %d", a.Data, i)
+		}
+		i++
+	}
+	if i != len(expect) {
+		t.Errorf("Expected %d ancestors, got %d", len(expect), i)
+	}
+	for range Ancestors(root) {
+		t.Error("The root node must not have ancestors")
+	}
+}
+
+func TestSiblingIterators(t *testing.T) {
+	const testDoc = "test.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parent := ElementByID(root, "TestNextSiblingByTag")
+	var children, following, preceding []*html.Node
+	for c := range ElementsOnly(Children(parent)) {
+		children = append(children, c)
+	}
+	expectIDs(t, "Children", children, "StartTestNextSiblingByTag", "pre1", "", "", "", "", "pre2", "EndTestNextSiblingByTag")
+
+	pre1 := ElementByID(root, "pre1")
+	for s := range ElementsOnly(FollowingSiblings(pre1)) {
+		if s.DataAtom == atom.Pre {
+			break
+		}
+		following = append(following, s)
+	}
+	expectIDs(t, "FollowingSiblings", following, "", "", "", "")
+
+	for s := range ElementsOnly(PrecedingSiblings(ElementByID(root, "pre2"))) {
+		preceding = append(preceding, s)
+	}
+	if len(preceding) != 6 || preceding[0].DataAtom != atom.Br || preceding[5] != parent.FirstChild.NextSibling {
+		t.Error("PrecedingSiblings must start with the closest sibling")
+	}
+}
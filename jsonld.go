@@ -0,0 +1,195 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"encoding/json"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+)
+
+//Returns the JSON-LD objects of all script elements of type application/ld+json in the subtree of doc, in document order.
+//The following breakage is tolerated: HTML comment and CDATA wrappers, trailing commas, raw line breaks in strings
+//and several top level objects in one script, either concatenated or separated by commas. Top level arrays are
+//flattened, the members of @graph objects replace their container and inherit its @context.
+//Scripts that cannot be decoded nevertheless are skipped. Returns nil if no objects were found.
+func JSONLD(doc *html.Node) []map[string]any {
+	var items []map[string]any
+	for _, s := range FindAll(doc, And(Tag(atom.Script), isHTMLElement, isJSONLDScript)) {
+		var values []any
+		if err := json.Unmarshal(repairJSON(stripScriptWrappers(TextContent(s))), &values); err != nil {
+			continue
+		}
+		for _, v := range values {
+			items = appendJSONLD(items, v, nil)
+		}
+	}
+	return items
+}
+
+//Returns the JSON-LD objects of doc that have at least one of the given types, see JSONLD.
+//Types are compared with the local name of the @type values, so "Product" matches "Product",
+//"schema:Product" and "https://schema.org/Product". Returns nil if no objects were found.
+func JSONLDByType(doc *html.Node, types ...string) []map[string]any {
+	var items []map[string]any
+	for _, item := range JSONLD(doc) {
+		if hasJSONLDType(item, types...) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isJSONLDScript(n *html.Node) bool {
+	typ, _ := attrValFold(n, "type")
+	typ, _, _ = strings.Cut(typ, ";")
+	return strings.EqualFold(strings.Trim(typ, asciiWhitespace), "application/ld+json")
+}
+
+//Appends the objects of v to items, flattening arrays and @graph objects. ctx is the @context inherited from an enclosing graph.
+func appendJSONLD(items []map[string]any, v any, ctx any) []map[string]any {
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			items = appendJSONLD(items, e, ctx)
+		}
+	case map[string]any:
+		if c, ok := v["@context"]; ok {
+			ctx = c
+		} else if ctx != nil {
+			v["@context"] = ctx
+		}
+		graph, ok := v["@graph"]
+		if !ok {
+			items = append(items, v)
+			break
+		}
+		delete(v, "@graph")
+		if len(v) > 1 || len(v) == 1 && v["@context"] == nil {
+			//the container describes an object of its own
+			items = append(items, v)
+		}
+		items = appendJSONLD(items, graph, ctx)
+	}
+	return items
+}
+
+//Returns the @type values of a JSON-LD object.
+func jsonldTypes(item map[string]any) []string {
+	switch t := item["@type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		var types []string
+		for _, e := range t {
+			if s, ok := e.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func hasJSONLDType(item map[string]any, types ...string) bool {
+	for _, t := range jsonldTypes(item) {
+		if i := strings.LastIndexAny(t, "/#:"); i >= 0 {
+			t = t[i+1:]
+		}
+		for _, typ := range types {
+			if t == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//Removes HTML comment and CDATA markers, including JavaScript comments that hide them, from the start and end of a script.
+func stripScriptWrappers(s string) string {
+	s = strings.Trim(s, asciiWhitespace)
+	for changed := true; changed; {
+		changed = false
+		for _, p := range []string{"<!--", "//<![CDATA[", "/*<![CDATA[*/", "/* <![CDATA[ */", "<![CDATA["} {
+			if strings.HasPrefix(s, p) {
+				s = strings.TrimLeft(s[len(p):], asciiWhitespace)
+				changed = true
+			}
+		}
+		for _, p := range []string{"-->", "//]]>", "/*]]>*/", "/* ]]> */", "]]>"} {
+			if strings.HasSuffix(s, p) {
+				s = strings.TrimRight(s[:len(s)-len(p)], asciiWhitespace)
+				changed = true
+			}
+		}
+	}
+	return s
+}
+
+//Turns the text of a JSON-LD script into a JSON array of its top level values. Trailing commas are removed,
+//missing commas between top level values are inserted and raw control characters in strings are escaped.
+func repairJSON(s string) []byte {
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '[')
+	depth := 0
+	inString, escaped := false, false
+	valueEnded := false //a top level value ended and no comma followed yet
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			case c == '\n':
+				b = append(b, `\n`...)
+				continue
+			case c == '\r':
+				b = append(b, `\r`...)
+				continue
+			case c == '\t':
+				b = append(b, `\t`...)
+				continue
+			}
+			b = append(b, c)
+			if !inString && depth == 0 {
+				valueEnded = true
+			}
+			continue
+		}
+		switch c {
+		case '"', '{', '[':
+			if depth == 0 && valueEnded {
+				b = append(b, ',')
+				valueEnded = false
+			}
+			if c == '"' {
+				inString = true
+			} else {
+				depth++
+			}
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				valueEnded = true
+			}
+		case ',':
+			j := i + 1
+			for j < len(s) && strings.IndexByte(asciiWhitespace, s[j]) >= 0 {
+				j++
+			}
+			if j == len(s) || s[j] == '}' || s[j] == ']' || s[j] == ',' {
+				continue
+			}
+			if depth == 0 {
+				valueEnded = false
+			}
+		}
+		b = append(b, c)
+	}
+	return append(b, ']')
+}
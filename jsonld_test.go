@@ -0,0 +1,82 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONLD(t *testing.T) {
+	const testDoc = "jsonld.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := JSONLD(root)
+	expect := []string{"Organization", "WebSite", `["Thing","schema:Product"]`, "http://schema.org/Article", "BreadcrumbList", "Article"}
+	if len(items) != len(expect) {
+		t.Fatalf("Expected %d objects, got %d: %v", len(expect), len(items), items)
+	}
+	for i, item := range items {
+		typ, _ := item["@type"].(string)
+		if typ == "" {
+			b, _ := json.Marshal(item["@type"])
+			typ = string(b)
+		}
+		if typ != expect[i] {
+			t.Errorf("Object %d: expected type %s, got %s", i, expect[i], typ)
+		}
+	}
+	if same := items[0]["sameAs"].([]any); len(same) != 2 {
+		t.Errorf("Expected 2 sameAs values, got %d", len(same))
+	}
+	if items[1]["@context"] != "https://schema.org" || items[2]["@context"] != "https://schema.org" {
+		t.Error("Graph members must inherit the context of the graph")
+	}
+	if items[2]["name"] != "Widget\nwith a line break" {
+		t.Errorf("Unexpected name %q", items[2]["name"])
+	}
+	if items[5]["headline"] != `Third "quoted" {not a brace}` {
+		t.Errorf("Unexpected headline %q", items[5]["headline"])
+	}
+
+	tests := []struct {
+		types  []string
+		expect int
+	}{
+		{[]string{"Product"}, 1},
+		{[]string{"Article"}, 2},
+		{[]string{"Article", "WebSite"}, 3},
+		{[]string{"Event"}, 0},
+	}
+	for _, test := range tests {
+		if found := JSONLDByType(root, test.types...); len(found) != test.expect {
+			t.Errorf("%v: expected %d objects, got %d", test.types, test.expect, len(found))
+		}
+	}
+	if JSONLDByType(root, "Event") != nil {
+		t.Error("JSONLDByType must return nil if nothing was found")
+	}
+}
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		in, expect string
+	}{
+		{`{"a": 1}`, `[{"a": 1}]`},
+		{`{"a": [1, 2,], }`, `[{"a": [1, 2] }]`},
+		{`{"a": 1}{"b": 2}`, `[{"a": 1},{"b": 2}]`},
+		{`{"a": 1}, {"b": 2},`, `[{"a": 1}, {"b": 2}]`},
+		{`{"a": "x,}"}`, `[{"a": "x,}"}]`},
+		{`{"a": "\"}{"}`, `[{"a": "\"}{"}]`},
+		{"{\"a\": \"1\t2\"}", `[{"a": "1\t2"}]`},
+	}
+	for _, test := range tests {
+		if s := string(repairJSON(test.in)); s != test.expect {
+			t.Errorf("%q: expected %q, got %q", test.in, test.expect, s)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/cond"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strings"
+)
+
+//Link is a hyperlink found in a document.
+type Link struct {
+	URL   *url.URL //the absolute URL the link points to
+	Href  string   //the href attribute as found in the document
+	Rel   []string //the lowercased tokens of the rel attribute
+	Text  string   //the anchor text with collapsed whitespace, the alt text for area elements
+	Title string
+	Node  *html.Node //the a, area or link element
+}
+
+//Returns the base URL of the document that contains n: the href of the first base element that has one,
+//resolved against pageURL, or pageURL itself if there is no such base element. pageURL may be nil.
+func BaseURL(n *html.Node, pageURL *url.URL) *url.URL {
+	base := Find(treeRoot(n), And(Tag(atom.Base), func(n *html.Node) bool {
+		return HasAttr(n, "", "href")
+	}))
+	if base != nil {
+		if u, err := parseURLAttr(AttrVal(base, "", "href")); err == nil {
+			if pageURL != nil {
+				return pageURL.ResolveReference(u)
+			}
+			return u
+		}
+	}
+	return pageURL
+}
+
+//Returns all hyperlinks in the document that contains doc, i.e. all a, area and link elements with an href attribute,
+//in document order. URLs are resolved against the document's base URL as returned by BaseURL.
+//Links whose href cannot be parsed are skipped. Returns nil if no links were found.
+func Links(doc *html.Node, pageURL *url.URL) []Link {
+	base := BaseURL(doc, pageURL)
+	var links []Link
+	for _, e := range FindAll(treeRoot(doc), Tag(atom.A, atom.Area, atom.Link)) {
+		href, ok := attrValFold(e, "href")
+		if !ok || e.Namespace != "" {
+			continue
+		}
+		u, err := parseURLAttr(href)
+		if err != nil {
+			continue
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		l := Link{
+			URL:   u,
+			Href:  href,
+			Rel:   cond.Tokens(strings.ToLower(AttrVal(e, "", "rel"))),
+			Title: AttrVal(e, "", "title"),
+			Node:  e,
+		}
+		switch e.DataAtom {
+		case atom.A:
+			l.Text = collapseSpace(InnerText(e))
+		case atom.Area:
+			l.Text = collapseSpace(AttrVal(e, "", "alt"))
+		}
+		links = append(links, l)
+	}
+	return links
+}
+
+//Returns the root of the tree n belongs to, usually the document node.
+func treeRoot(n *html.Node) *html.Node {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return n
+}
+
+//Parses the value of an URL attribute like a browser, ignoring leading and trailing whitespace as well as tabs and newlines.
+func parseURLAttr(v string) (*url.URL, error) {
+	v = strings.Trim(v, asciiWhitespace)
+	v = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(v)
+	return url.Parse(v)
+}
+
+//Replaces every sequence of whitespace in s by a single space and trims s.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
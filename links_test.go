@@ -0,0 +1,82 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBaseURL(t *testing.T) {
+	const testDoc = "links.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, _ := url.Parse("https://example.net/page/index.html")
+	if b := BaseURL(root, page); b.String() != "https://example.net/docs/" {
+		t.Errorf("Expected base \"https://example.net/docs/\", got \"%s\"", b)
+	}
+	if b := BaseURL(root, nil); b.String() != "/docs/" {
+		t.Errorf("Expected base \"/docs/\", got \"%s\"", b)
+	}
+
+	plain, err := parseTestFile("test.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if BaseURL(plain, page) != page {
+		t.Error("Expected the page URL for documents without base element")
+	}
+}
+
+func TestLinks(t *testing.T) {
+	const testDoc = "links.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, _ := url.Parse("https://example.net/page/index.html")
+	links := Links(root, page)
+	expect := []struct {
+		url, text string
+		rel       []string
+	}{
+		{"https://example.net/docs/style.css", "", []string{"stylesheet", "preload"}},
+		{"https://example.net/docs/intro.html#top", "The intro", nil},
+		{"https://example.org/abs", "Absolute", []string{"nofollow", "noopener"}},
+		{"https://cdn.example.com/x", "Protocol relative", nil},
+		{"https://example.net/area", "Area text", nil},
+	}
+	if len(links) != len(expect) {
+		t.Fatalf("Expected %d links, got %d", len(expect), len(links))
+	}
+	for i, l := range links {
+		if l.URL.String() != expect[i].url || l.Text != expect[i].text || strings.Join(l.Rel, " ") != strings.Join(expect[i].rel, " ") {
+			t.Errorf("Link %d: expected %v, got %s %q %q", i, expect[i], l.URL, l.Text, l.Rel)
+		}
+	}
+	if l := Links(links[1].Node, page); len(l) != len(links) {
+		t.Errorf("Links must search the whole document, got %d links", len(l))
+	}
+	if links[0].Title != "Main style" || links[2].Href != " https://example.org/abs " || links[1].Node.Data != "a" {
+		t.Error("Unexpected link attributes")
+	}
+}
+
+func TestLinksRel(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader("<a href=\"/\" rel=\"nofollow\u00a0x\tNoOpener\">a</a>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := Links(doc, nil); len(l) != 1 || !reflect.DeepEqual(l[0].Rel, []string{"nofollow\u00a0x", "noopener"}) {
+		t.Errorf("Unexpected links %v", l)
+	}
+}
@@ -0,0 +1,565 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+//MarkdownOptions configures ToMarkdown, the zero value selects the defaults.
+type MarkdownOptions struct {
+	PageURL        *url.URL //if not nil, link and image URLs are resolved against the document's base URL, see BaseURL
+	ReferenceLinks bool     //write links and images as references like [text][1], the definitions follow at the end
+	BulletMarker   byte     //marker of unordered list items, '-' (default), '*' or '+'
+}
+
+//Converts n and its descendants to CommonMark with the GitHub Flavored Markdown extensions for tables, strikethrough
+//and task lists. Headings become ATX headings, pre elements fenced code blocks whose info string is taken from a
+//"language-*" class of the pre element or its code child, and tables are built from the table model of NewTable,
+//the first header row or the first row becomes the table header. Whitespace is collapsed like InnerText does and
+//Markdown metacharacters in text are escaped. Elements that are not rendered by default and foreign elements like
+//svg are skipped. Returns the empty string if n has no content, otherwise the result ends with a newline.
+//Unlike the traversal functions, the conversion is recursive and limited by the nesting depth the goroutine stack allows.
+func ToMarkdown(n *html.Node, opts *MarkdownOptions) string {
+	c := &mdConverter{refIDs: make(map[string]int)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.BulletMarker != '*' && c.opts.BulletMarker != '+' {
+		c.opts.BulletMarker = '-'
+	}
+	if c.opts.PageURL != nil {
+		c.base = BaseURL(n, c.opts.PageURL)
+	}
+	var s string
+	switch {
+	case n.Type == html.DocumentNode:
+		s = joinMarkdownBlocks(c.blocks(n), "\n\n")
+	case c.skip(n):
+	case isMarkdownBlock(n):
+		s = c.block(n)
+	default:
+		s = c.paragraph(c.inline(n))
+	}
+	if len(c.refs) > 0 {
+		s += "\n\n" + strings.Join(c.refs, "\n")
+	}
+	if s = strings.Trim(s, "\n"); s == "" {
+		return ""
+	}
+	return s + "\n"
+}
+
+type mdConverter struct {
+	opts   MarkdownOptions
+	base   *url.URL
+	refs   []string       //link reference definitions
+	refIDs map[string]int //maps destination and title to the number of a reference definition
+}
+
+//mdBlock is a converted block element or paragraph.
+type mdBlock struct {
+	text string
+	list byte //the bullet marker of an unordered list, '.' for an ordered list, 0 for other blocks
+}
+
+func isMarkdownBlock(n *html.Node) bool {
+	return isHTMLElement(n) && (blockElements[n.DataAtom] || n.DataAtom == atom.Xmp)
+}
+
+//Reports whether n does not contribute to the output.
+func (c *mdConverter) skip(n *html.Node) bool {
+	switch n.Type {
+	case html.TextNode:
+		return false
+	case html.ElementNode:
+		return !isHTMLElement(n) || hiddenElements[n.DataAtom] || HasAttr(n, "", "hidden")
+	}
+	return true
+}
+
+//Converts the children of n to blocks. Consecutive text and inline elements form a paragraph.
+func (c *mdConverter) blocks(n *html.Node) []mdBlock {
+	var blocks []mdBlock
+	var inline strings.Builder
+	add := func(s string, list byte) {
+		if s != "" {
+			blocks = append(blocks, mdBlock{s, list})
+		}
+	}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		switch {
+		case c.skip(ch):
+		case isMarkdownBlock(ch):
+			add(c.paragraph(inline.String()), 0)
+			inline.Reset()
+			add(c.block(ch), c.listKind(ch))
+		default:
+			inline.WriteString(c.inline(ch))
+		}
+	}
+	add(c.paragraph(inline.String()), 0)
+	return blocks
+}
+
+//Joins blocks by sep. Adjacent lists of the same kind are separated by an empty HTML comment, so they do not merge into one list.
+func joinMarkdownBlocks(blocks []mdBlock, sep string) string {
+	var b strings.Builder
+	for i, bl := range blocks {
+		if i > 0 {
+			b.WriteString(sep)
+			if bl.list != 0 && bl.list == blocks[i-1].list {
+				b.WriteString("<!-- -->" + sep)
+			}
+		}
+		b.WriteString(bl.text)
+	}
+	return b.String()
+}
+
+//Returns the list kind of the block element n, see mdBlock.
+func (c *mdConverter) listKind(n *html.Node) byte {
+	switch n.DataAtom {
+	case atom.Ol:
+		return '.'
+	case atom.Ul, atom.Menu, atom.Dir:
+		return c.opts.BulletMarker
+	}
+	return 0
+}
+
+//Converts the block element n.
+func (c *mdConverter) block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		s := strings.ReplaceAll(c.paragraph(c.inlines(n)), "\\\n", " ")
+		if s == "" {
+			return ""
+		}
+		return strings.Repeat("#", int(n.Data[1]-'0')) + " " + s
+	case atom.Hr:
+		return "***"
+	case atom.Pre, atom.Listing, atom.Xmp, atom.Plaintext:
+		return c.codeBlock(n)
+	case atom.Blockquote:
+		return prefixLines(joinMarkdownBlocks(c.blocks(n), "\n\n"), "> ", "> ")
+	case atom.Ul, atom.Ol, atom.Menu, atom.Dir:
+		return c.list(n)
+	case atom.Table:
+		return c.table(n)
+	}
+	return joinMarkdownBlocks(c.blocks(n), "\n\n")
+}
+
+//Converts a list. The list is tight if no item contains a p element, a block after a nested list or an ordered list
+//that does not start with 1 after a paragraph, because such a list cannot interrupt a paragraph.
+func (c *mdConverter) list(n *html.Node) string {
+	var items []*html.Node
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		if isHTMLElement(ch) && ch.DataAtom == atom.Li && !c.skip(ch) {
+			items = append(items, ch)
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	ordered := n.DataAtom == atom.Ol
+	reversed := ordered && HasAttr(n, "", "reversed")
+	num := 1
+	if reversed {
+		num = len(items)
+	}
+	if v, err := strconv.Atoi(strings.Trim(AttrVal(n, "", "start"), asciiWhitespace)); ordered && err == nil {
+		num = v
+	}
+	tight := true
+	contents := make([][]mdBlock, len(items))
+	for i, li := range items {
+		contents[i] = c.blocks(li)
+		for ch := li.FirstChild; ch != nil; ch = ch.NextSibling {
+			if isHTMLElement(ch) && ch.DataAtom == atom.P {
+				tight = false
+			}
+		}
+		for j, bl := range contents[i] {
+			if j > 0 && (bl.list == 0 || bl.list == '.' && contents[i][j-1].list == 0 && !strings.HasPrefix(bl.text, "1.")) {
+				tight = false
+			}
+		}
+	}
+	sep := "\n"
+	if !tight {
+		sep = "\n\n"
+	}
+	lines := make([]string, len(items))
+	for i, li := range items {
+		marker := string(c.opts.BulletMarker)
+		if ordered {
+			if v, err := strconv.Atoi(strings.Trim(AttrVal(li, "", "value"), asciiWhitespace)); err == nil {
+				num = v
+			}
+			marker = strconv.Itoa(max(num, 0)) + "."
+			if reversed {
+				num--
+			} else {
+				num++
+			}
+		}
+		content := joinMarkdownBlocks(contents[i], sep)
+		if content == "" {
+			lines[i] = marker
+			continue
+		}
+		lines[i] = prefixLines(content, marker+" ", strings.Repeat(" ", len(marker)+1))
+	}
+	return strings.Join(lines, sep)
+}
+
+//Prefixes the first line of s with first and the other non-empty lines with rest. Empty lines get the trimmed rest.
+func prefixLines(s, first, rest string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		switch {
+		case i == 0:
+			lines[i] = first + l
+		case l == "":
+			lines[i] = strings.TrimRight(rest, " ")
+		default:
+			lines[i] = rest + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+//Converts a preformatted element to a fenced code block.
+func (c *mdConverter) codeBlock(n *html.Node) string {
+	var b strings.Builder
+	for d := range Descendants(n) {
+		switch {
+		case d.Type == html.TextNode:
+			b.WriteString(d.Data)
+		case isHTMLElement(d) && d.DataAtom == atom.Br:
+			b.WriteByte('\n')
+		}
+	}
+	text := strings.TrimSuffix(b.String(), "\n")
+	lang := codeLanguage(n)
+	if code := firstElementChild(n); lang == "" && code != nil && code.DataAtom == atom.Code {
+		lang = codeLanguage(code)
+	}
+	fence := strings.Repeat("`", max(3, longestRun(text, '`')+1))
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+//Returns the language of a "language-*" class of n.
+func codeLanguage(n *html.Node) string {
+	for _, name := range Classes(n).Values() {
+		if lang, ok := strings.CutPrefix(name, "language-"); ok && lang != "" && !strings.Contains(lang, "`") {
+			return lang
+		}
+	}
+	return ""
+}
+
+func firstElementChild(n *html.Node) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
+
+//Returns the length of the longest run of ch in s.
+func longestRun(s string, ch byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ch {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+//Converts a table to a GFM table, the caption becomes a paragraph in front of it.
+func (c *mdConverter) table(n *html.Node) string {
+	t, err := NewTable(n)
+	if err != nil || len(t.Rows) == 0 || len(t.Rows[0]) == 0 {
+		return ""
+	}
+	cols := len(t.Rows[0])
+	text := func(row, col int) string {
+		cell := t.Rows[row][col]
+		if cell.Node == nil || cell.Row != row || cell.Col != col {
+			return ""
+		}
+		return c.cell(cell.Node)
+	}
+	var rows [][]string
+	header := make([]string, cols)
+	head := max(t.HeadRows, 1)
+	for col := range header {
+		var parts []string
+		for row := 0; row < head; row++ {
+			if s := text(row, col); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		header[col] = strings.Join(parts, " ")
+	}
+	rows = append(rows, header)
+	for row := head; row < len(t.Rows); row++ {
+		cells := make([]string, cols)
+		for col := range cells {
+			cells[col] = text(row, col)
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, cols)
+	for col := range widths {
+		widths[col] = 3
+		for _, r := range rows {
+			widths[col] = max(widths[col], utf8.RuneCountInString(r[col]))
+		}
+	}
+	delim := make([]string, cols)
+	for col := range delim {
+		align := ""
+		if cell := t.Rows[0][col]; cell.Node != nil {
+			align, _ = attrValFold(cell.Node, "align")
+		}
+		switch strings.ToLower(strings.Trim(align, asciiWhitespace)) {
+		case "left":
+			delim[col] = ":" + strings.Repeat("-", widths[col]-1)
+		case "center":
+			delim[col] = ":" + strings.Repeat("-", widths[col]-2) + ":"
+		case "right":
+			delim[col] = strings.Repeat("-", widths[col]-1) + ":"
+		default:
+			delim[col] = strings.Repeat("-", widths[col])
+		}
+	}
+	rows = append(rows[:1], append([][]string{delim}, rows[1:]...)...)
+
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		cells := make([]string, cols)
+		for col, s := range r {
+			cells[col] = s + strings.Repeat(" ", widths[col]-utf8.RuneCountInString(s))
+		}
+		lines[i] = "| " + strings.Join(cells, " | ") + " |"
+	}
+	s := strings.Join(lines, "\n")
+	if caption := c.paragraph(escapeMarkdown(collapseWhitespace(t.Caption))); caption != "" {
+		s = caption + "\n\n" + s
+	}
+	return s
+}
+
+//Converts the content of a table cell to a single line, line breaks become br elements.
+func (c *mdConverter) cell(n *html.Node) string {
+	s := joinMarkdownBlocks(c.blocks(n), "\n\n")
+	s = strings.NewReplacer("\\\n", "<br>", "\n\n", "<br>", "\n", " ").Replace(s)
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+//Converts the inline content of n. Line breaks are returned as "\n", they are turned into hard line breaks by paragraph.
+func (c *mdConverter) inline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return escapeMarkdown(collapseWhitespace(n.Data))
+	}
+	if c.skip(n) {
+		return ""
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.B, atom.Strong:
+		return wrapInline(c.inlines(n), "**", "**")
+	case atom.I, atom.Em:
+		return wrapInline(c.inlines(n), "*", "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrapInline(c.inlines(n), "~~", "~~")
+	case atom.Q:
+		return wrapInline(c.inlines(n), `"`, `"`)
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		return codeSpan(TextContent(n))
+	case atom.A:
+		href, ok := attrValFold(n, "href")
+		if !ok {
+			return c.inlines(n)
+		}
+		dest := c.url(href)
+		text := c.inlines(n)
+		if strings.Trim(text, " \n") == "" {
+			text = escapeMarkdown(dest)
+		}
+		title, _ := attrValFold(n, "title")
+		return wrapInline(text, "[", "]"+c.destination(dest, title))
+	case atom.Img:
+		alt, _ := attrValFold(n, "alt")
+		alt = escapeMarkdown(strings.Trim(collapseWhitespace(alt), " "))
+		src, ok := attrValFold(n, "src")
+		if !ok || strings.Trim(src, asciiWhitespace) == "" {
+			return alt
+		}
+		title, _ := attrValFold(n, "title")
+		return "![" + alt + "]" + c.destination(c.url(src), title)
+	case atom.Input:
+		if v, _ := attrValFold(n, "type"); strings.EqualFold(v, "checkbox") && n.Parent != nil && n.Parent.DataAtom == atom.Li {
+			if HasAttr(n, "", "checked") {
+				return "[x] "
+			}
+			return "[ ] "
+		}
+		return ""
+	}
+	if isMarkdownBlock(n) {
+		return " " + c.inlines(n) + " "
+	}
+	return c.inlines(n)
+}
+
+func (c *mdConverter) inlines(n *html.Node) string {
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		b.WriteString(c.inline(ch))
+	}
+	return b.String()
+}
+
+//Resolves the URL of a link or image if a base URL is known.
+func (c *mdConverter) url(v string) string {
+	v = strings.Trim(v, asciiWhitespace)
+	if c.base != nil {
+		if u, err := parseURLAttr(v); err == nil {
+			return c.base.ResolveReference(u).String()
+		}
+	}
+	return v
+}
+
+//Returns the destination part of a link or image, which is an inline destination or a reference label.
+func (c *mdConverter) destination(dest, title string) string {
+	if dest == "" || strings.ContainsAny(dest, " <>()\\"+asciiWhitespace) {
+		dest = "<" + strings.NewReplacer("\\", "\\\\", "<", "\\<", ">", "\\>", "\n", "%0A", "\r", "%0D").Replace(dest) + ">"
+	}
+	if title = strings.Trim(collapseWhitespace(title), " "); title != "" {
+		dest += ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(title) + `"`
+	}
+	if !c.opts.ReferenceLinks {
+		return "(" + dest + ")"
+	}
+	id, ok := c.refIDs[dest]
+	if !ok {
+		id = len(c.refs) + 1
+		c.refIDs[dest] = id
+		c.refs = append(c.refs, fmt.Sprintf("[%d]: %s", id, dest))
+	}
+	return fmt.Sprintf("[%d]", id)
+}
+
+//Turns collapsed inline content into paragraph text: spaces are collapsed and trimmed at line ends, line breaks become
+//hard line breaks and characters at the start of lines that would start a block are escaped.
+func (c *mdConverter) paragraph(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = escapeLineStart(strings.Trim(mdSpaces.ReplaceAllString(l, " "), " "))
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+var (
+	mdSpaces     = regexp.MustCompile(` {2,}`)
+	mdWhitespace = regexp.MustCompile(`[ \t\n\f\r]+`)
+	mdEntity     = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+)
+
+//Replaces every sequence of ASCII whitespace in s by a single space.
+func collapseWhitespace(s string) string {
+	return mdWhitespace.ReplaceAllString(s, " ")
+}
+
+//Puts s between open and closing, leading and trailing spaces and line breaks of s are moved outside.
+func wrapInline(s, open, closing string) string {
+	trimmed := strings.Trim(s, " \n")
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + open + trimmed + closing + s[start+len(trimmed):]
+}
+
+//Returns s as code span, whitespace is collapsed.
+func codeSpan(s string) string {
+	s = strings.Trim(collapseWhitespace(s), " ")
+	if s == "" {
+		return ""
+	}
+	fence := strings.Repeat("`", longestRun(s, '`')+1)
+	if s[0] == '`' || s[len(s)-1] == '`' {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+//Escapes the characters of text that have a meaning in Markdown inline content.
+//Underscores within words and ampersands that do not start an entity are kept.
+func escapeMarkdown(s string) string {
+	alnum := func(i int) bool {
+		return i >= 0 && i < len(s) && ('a' <= s[i] && s[i] <= 'z' || 'A' <= s[i] && s[i] <= 'Z' || '0' <= s[i] && s[i] <= '9')
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', '`', '*', '[', ']', '<', '~':
+			b.WriteByte('\\')
+		case '_':
+			if !alnum(i-1) || !alnum(i+1) {
+				b.WriteByte('\\')
+			}
+		case '&':
+			if mdEntity.MatchString(s[i:]) {
+				b.WriteByte('\\')
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+//Escapes a character at the start of the paragraph line l that would start a heading, blockquote, list or thematic break.
+func escapeLineStart(l string) string {
+	if l == "" {
+		return l
+	}
+	switch l[0] {
+	case '#', '>', '-', '+', '=':
+		return `\` + l
+	}
+	i := 0
+	for i < len(l) && i < 10 && '0' <= l[i] && l[i] <= '9' {
+		i++
+	}
+	if i > 0 && i < len(l) && (l[i] == '.' || l[i] == ')') && (i+1 == len(l) || l[i+1] == ' ') {
+		return l[:i] + `\` + l[i:]
+	}
+	return l
+}
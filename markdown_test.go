@@ -0,0 +1,93 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToMarkdown(t *testing.T) {
+	const testDoc = "markdown.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := os.ReadFile(filepath.Join(htmlDir, "markdown.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://example.org/index.html")
+	article := ElementByID(root, "article")
+	if md := ToMarkdown(article, &MarkdownOptions{PageURL: page}); md != string(expect) {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expect, md)
+	}
+
+	md := ToMarkdown(article, &MarkdownOptions{ReferenceLinks: true})
+	refs := "[1]: post.html \"A \\\"post\\\"\"\n[2]: /img/soup.png \"Soup\"\n[3]: icon.png\n[4]: https://example.net/\n"
+	if !strings.Contains(md, "a [relative link][1] and") || !strings.Contains(md, "[![icon][3]][4]") || !strings.HasSuffix(md, refs) {
+		t.Errorf("Unexpected reference links:\n%s", md)
+	}
+}
+
+func TestToMarkdownFragments(t *testing.T) {
+	tests := []struct {
+		src    string
+		opts   *MarkdownOptions
+		expect string
+	}{
+		{"", nil, ""},
+		{"<p> \n </p><script>x()</script>", nil, ""},
+		{"<ul><li>a</li></ul><ul><li>b</li></ul>", nil, "- a\n\n<!-- -->\n\n- b\n"},
+		{"<ul><li>a<ul><li>b</li></ul></li></ul><ol><li>c</li></ol>", &MarkdownOptions{BulletMarker: '*'}, "* a\n  * b\n\n1. c\n"},
+		{"<ol start=9><li>a<li>b</ol>", nil, "9. a\n10. b\n"},
+		{"<li>stray</li>", nil, "stray\n"},
+		{"x<b> bold </b>y<em></em>", nil, "x **bold** y\n"},
+		{"<a>no href</a> <a href=\"a b.html\"></a>", nil, "no href [a b.html](<a b.html>)\n"},
+		{"<h2>Title<br>split</h2>", nil, "## Title split\n"},
+		{"<pre>a\n\n b</pre>", nil, "```\na\n\n b\n```\n"},
+		{"<pre class=\"language-html\"><b>&lt;p&gt;</b><br>x</pre>", nil, "```html\n<p>\nx\n```\n"},
+		{"<code>`</code> <kbd> a  b </kbd>", nil, "`` ` `` `a b`\n"},
+		{"<p>+1 = 2<br>100) done<br>100)x</p>", nil, "\\+1 = 2\\\n100\\) done\\\n100)x\n"},
+		{"<table><tr><td>a</td><td>b</td></tr><tr><td>c</td></tr></table>", nil, "| a   | b   |\n| --- | --- |\n| c   |     |\n"},
+		{"<svg><text>svg</text></svg>text", nil, "text\n"},
+	}
+	for _, test := range tests {
+		nodes, err := ParseFragment(nil, test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := &html.Node{Type: html.DocumentNode}
+		for _, n := range nodes {
+			doc.AppendChild(n)
+		}
+		if md := ToMarkdown(doc, test.opts); md != test.expect {
+			t.Errorf("%q: expected %q, got %q", test.src, test.expect, md)
+		}
+	}
+
+	if md := ToMarkdown(TextNode("a * b"), nil); md != "a \\* b\n" {
+		t.Errorf("Unexpected Markdown of a text node %q", md)
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		text, expect string
+	}{
+		{`a\b`, `a\\b`},
+		{"snake_case _x_ a_", `snake_case \_x\_ a\_`},
+		{"AT&T &amp; &#42; &x", `AT&T \&amp; \&#42; &x`},
+		{"~`*[]<>!", "\\~\\`\\*\\[\\]\\<>!"},
+	}
+	for _, test := range tests {
+		if s := escapeMarkdown(test.text); s != test.expect {
+			t.Errorf("%q: expected %q, got %q", test.text, test.expect, s)
+		}
+	}
+}
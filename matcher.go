@@ -0,0 +1,101 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/cond"
+	"github.com/jwdev42/rottensoup/internal/nav"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"regexp"
+)
+
+//Matcher reports whether a node satisfies a condition.
+//Matchers can be combined with And, Or and Not and passed to Find, FindAll and FindN.
+//The Match method of a compiled Selector is a valid Matcher as well.
+type Matcher func(*html.Node) bool
+
+//Returns a Matcher that matches if all given matchers match. And without arguments matches every node.
+func And(m ...Matcher) Matcher {
+	return func(n *html.Node) bool {
+		for _, f := range m {
+			if !f(n) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+//Returns a Matcher that matches if at least one of the given matchers matches. Or without arguments matches no node.
+func Or(m ...Matcher) Matcher {
+	return func(n *html.Node) bool {
+		for _, f := range m {
+			if f(n) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+//Returns a Matcher that matches if m does not match.
+func Not(m Matcher) Matcher {
+	return func(n *html.Node) bool {
+		return !m(n)
+	}
+}
+
+//Returns a Matcher that matches all elements that contain all given attributes.
+func Attr(attr ...html.Attribute) Matcher {
+	return And(Type(html.ElementNode), cond.Attrs(attr...))
+}
+
+//Returns a Matcher that matches all elements that have an attribute where namespace and key are equal
+//to the function args and where the attribute's val matches the given regular expression.
+func AttrRegex(namespace, key string, val *regexp.Regexp) Matcher {
+	return And(Type(html.ElementNode), cond.AttrValByRegex(namespace, key, val))
+}
+
+//Returns a Matcher that matches all elements that are a member of all given classes.
+func Class(name ...string) Matcher {
+	return And(Type(html.ElementNode), cond.ClassNames(name...))
+}
+
+//Returns a Matcher that matches all elements that match at least one of the given tags.
+func Tag(tag ...atom.Atom) Matcher {
+	return And(Type(html.ElementNode), cond.Tag(tag...))
+}
+
+//Returns a Matcher that matches all nodes of NodeType t.
+func Type(t html.NodeType) Matcher {
+	return cond.Type(t)
+}
+
+//Executes depth-first search on n and all of its descendants and returns the first node matched by m.
+//Returns nil if no such node was found.
+func Find(n *html.Node, m Matcher) *html.Node {
+	nodes := make([]*html.Node, 0, 1)
+	nav.DFS(n, cond.Match(&nodes, true, m), nil)
+	if len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+//Executes depth-first search on n and all of its descendants and returns all nodes matched by m.
+//Returns nil if no matches were found.
+func FindAll(n *html.Node, m Matcher) []*html.Node {
+	return FindN(n, m, -1)
+}
+
+//Executes depth-first search on n and all of its descendants and returns the first limit nodes matched by m.
+//If limit is negative, all matches are returned. Returns nil if no matches were found.
+func FindN(n *html.Node, m Matcher, limit int) []*html.Node {
+	nodes := make([]*html.Node, 0, 10)
+	nav.DFS(n, cond.MatchN(&nodes, limit, m), nil)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes
+}
@@ -0,0 +1,96 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"regexp"
+	"testing"
+)
+
+func TestMatcherCombinators(t *testing.T) {
+	e := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.P,
+		Data:     "p",
+		Attr: []html.Attribute{
+			{Key: "class", Val: "intro text"},
+			{Key: "lang", Val: "de"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		m      Matcher
+		expect bool
+	}{
+		{"Tag", Tag(atom.Div, atom.P), true},
+		{"Tag mismatch", Tag(atom.Div), false},
+		{"Attr", Attr(html.Attribute{Key: "lang", Val: "de"}), true},
+		{"AttrRegex", AttrRegex("", "class", regexp.MustCompile("^intro")), true},
+		{"Class", Class("text", "intro"), true},
+		{"Class mismatch", Class("text", "outro"), false},
+		{"Type", Type(html.ElementNode), true},
+		{"And", And(Tag(atom.P), Class("intro")), true},
+		{"And mismatch", And(Tag(atom.P), Class("outro")), false},
+		{"And empty", And(), true},
+		{"Or", Or(Tag(atom.Div), Class("intro")), true},
+		{"Or empty", Or(), false},
+		{"Not", Not(Tag(atom.P)), false},
+	}
+	for _, test := range tests {
+		if res := test.m(e); res != test.expect {
+			t.Errorf("%s: expected %t, got %t", test.name, test.expect, res)
+		}
+	}
+
+	text := &html.Node{Type: html.TextNode, Data: "text"}
+	if Tag(0)(text) || Attr()(text) || Class()(text) {
+		t.Error("Element matchers must not match text nodes")
+	}
+}
+
+func TestFindN(t *testing.T) {
+	const testDoc = "by_tag.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := FindAll(root, Tag(atom.P))
+	if len(all) != 4 {
+		t.Fatalf("Expected 4 elements, got %d", len(all))
+	}
+	for limit := 0; limit <= 5; limit++ {
+		nodes := FindN(root, Tag(atom.P), limit)
+		expect := limit
+		if expect > len(all) {
+			expect = len(all)
+		}
+		if len(nodes) != expect {
+			t.Errorf("Limit %d: expected %d elements, got %d", limit, expect, len(nodes))
+		}
+		for i, n := range nodes {
+			if n != all[i] {
+				t.Errorf("Limit %d: element %d differs from FindAll", limit, i)
+			}
+		}
+	}
+	if FindN(root, Tag(atom.P), 0) != nil {
+		t.Error("Expected nil for limit 0")
+	}
+	if Find(root, Tag(atom.P)) != all[0] {
+		t.Error("Find returned the wrong element")
+	}
+	if Find(root, And(Tag(atom.A), Not(AttrRegex("", "href", regexp.MustCompile("google"))))) == nil {
+		t.Error("Find did not find the non-google link")
+	}
+	if FindAll(root, Tag(atom.Table)) != nil {
+		t.Error("Expected return value nil")
+	}
+	if Find(root, Type(html.DocumentNode)) != root {
+		t.Error("Find should include the start node")
+	}
+}
@@ -0,0 +1,300 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strconv"
+	"strings"
+)
+
+//PageMetadata holds the metadata of a document as returned by Metadata.
+//URLs are reported as found in the document, use Absolutize beforehand to get absolute URLs.
+type PageMetadata struct {
+	Title       string
+	Description string
+	Keywords    []string
+	Canonical   string
+	Alternates  []Alternate //alternate links with a hreflang attribute
+	Robots      []string    //the lowercased directives of the robots meta element
+	ThemeColor  string
+	Icons       []Icon
+	Feeds       []Feed
+	OpenGraph   OpenGraph
+	Twitter     TwitterCard
+	Names       map[string][]string //contents of all meta elements with a name attribute by lowercased name
+	Properties  map[string][]string //contents of all meta elements with a property attribute by lowercased property
+	HTTPEquiv   map[string]string   //contents of all meta elements with an http-equiv attribute by lowercased pragma
+}
+
+//Alternate is a translation of a document.
+type Alternate struct {
+	Hreflang string
+	Href     string
+}
+
+//Icon is a favicon or another icon link of a document.
+type Icon struct {
+	Rel   string //the lowercased rel attribute, e.g. "icon" or "apple-touch-icon"
+	Href  string
+	Sizes string
+	Type  string
+}
+
+//Feed is a link to an RSS, Atom or JSON feed of a document.
+type Feed struct {
+	Type  string
+	Title string
+	Href  string
+}
+
+//OpenGraph holds the OpenGraph properties of a document. Properties not covered by a field are available by
+//PageMetadata.Properties.
+type OpenGraph struct {
+	Type             string
+	Title            string
+	Description      string
+	URL              string
+	SiteName         string
+	Locale           string
+	LocaleAlternates []string
+	Images           []OpenGraphMedia
+	Videos           []OpenGraphMedia
+	Audio            []OpenGraphMedia
+}
+
+//OpenGraphMedia is an image, video or audio object of an OpenGraph array together with its structured properties.
+type OpenGraphMedia struct {
+	URL       string
+	SecureURL string
+	Type      string
+	Alt       string
+	Width     int
+	Height    int
+}
+
+//TwitterCard holds the Twitter card fields of a document.
+type TwitterCard struct {
+	Card        string
+	Site        string
+	Creator     string
+	Title       string
+	Description string
+	Image       string
+	ImageAlt    string
+}
+
+//Feed types recognized by Metadata.
+var feedTypes = map[string]bool{
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/rss+xml":   true,
+}
+
+//Rel tokens of icon links.
+var iconRels = map[string]bool{
+	"apple-touch-icon":             true,
+	"apple-touch-icon-precomposed": true,
+	"icon":                         true,
+	"mask-icon":                    true,
+}
+
+//Returns the metadata of the document that contains doc, collected from its title, meta and link elements.
+//Attribute names and the values of name, property, http-equiv and rel attributes are matched case-insensitively.
+//A meta element with both a name and a property attribute contributes to both. OpenGraph and Twitter card fields
+//are read from property as well as name attributes, as both forms are common. If a field is set by several
+//elements, the first one wins.
+func Metadata(doc *html.Node) *PageMetadata {
+	m := &PageMetadata{
+		Names:      make(map[string][]string),
+		Properties: make(map[string][]string),
+		HTTPEquiv:  make(map[string]string),
+	}
+	doc = treeRoot(doc)
+	if t := Find(doc, And(Tag(atom.Title), isHTMLElement)); t != nil {
+		m.Title = collapseSpace(TextContent(t))
+	}
+	for _, e := range FindAll(doc, And(Tag(atom.Meta, atom.Link), isHTMLElement)) {
+		if e.DataAtom == atom.Meta {
+			m.meta(e)
+		} else {
+			m.link(e)
+		}
+	}
+	return m
+}
+
+func (m *PageMetadata) meta(e *html.Node) {
+	content, _ := attrValFold(e, "content")
+	content = strings.Trim(content, asciiWhitespace)
+	if v, ok := attrValFold(e, "http-equiv"); ok {
+		pragma := strings.ToLower(strings.Trim(v, asciiWhitespace))
+		if _, ok := m.HTTPEquiv[pragma]; !ok {
+			m.HTTPEquiv[pragma] = content
+		}
+		return
+	}
+	if v, ok := attrValFold(e, "property"); ok {
+		for _, prop := range strings.Fields(strings.ToLower(v)) {
+			m.Properties[prop] = append(m.Properties[prop], content)
+			m.property(prop, content, false)
+		}
+	}
+	if v, ok := attrValFold(e, "name"); ok {
+		name := strings.ToLower(strings.Trim(v, asciiWhitespace))
+		m.Names[name] = append(m.Names[name], content)
+		m.name(name, content)
+		m.property(name, content, true)
+	}
+}
+
+func (m *PageMetadata) name(name, content string) {
+	switch name {
+	case "description":
+		setFirst(&m.Description, content)
+	case "keywords":
+		if m.Keywords == nil {
+			m.Keywords = splitList(content, ",")
+		}
+	case "robots":
+		if m.Robots == nil {
+			m.Robots = splitList(strings.ToLower(content), ",")
+		}
+	case "theme-color":
+		setFirst(&m.ThemeColor, content)
+	}
+}
+
+//Handles OpenGraph and Twitter card properties. byName is true if prop was found in a name attribute,
+//OpenGraph arrays are only built from property attributes so that documents that use both forms do not get duplicates.
+func (m *PageMetadata) property(prop, content string, byName bool) {
+	og := &m.OpenGraph
+	tw := &m.Twitter
+	switch prop {
+	case "og:type":
+		setFirst(&og.Type, content)
+	case "og:title":
+		setFirst(&og.Title, content)
+	case "og:description":
+		setFirst(&og.Description, content)
+	case "og:url":
+		setFirst(&og.URL, content)
+	case "og:site_name":
+		setFirst(&og.SiteName, content)
+	case "og:locale":
+		setFirst(&og.Locale, content)
+	case "og:locale:alternate":
+		if !byName {
+			og.LocaleAlternates = append(og.LocaleAlternates, content)
+		}
+	case "twitter:card":
+		setFirst(&tw.Card, content)
+	case "twitter:site":
+		setFirst(&tw.Site, content)
+	case "twitter:creator":
+		setFirst(&tw.Creator, content)
+	case "twitter:title":
+		setFirst(&tw.Title, content)
+	case "twitter:description":
+		setFirst(&tw.Description, content)
+	case "twitter:image", "twitter:image:src":
+		setFirst(&tw.Image, content)
+	case "twitter:image:alt":
+		setFirst(&tw.ImageAlt, content)
+	default:
+		if byName {
+			return
+		}
+		for _, kind := range []struct {
+			prefix string
+			media  *[]OpenGraphMedia
+		}{
+			{"og:image", &og.Images},
+			{"og:video", &og.Videos},
+			{"og:audio", &og.Audio},
+		} {
+			if prop == kind.prefix || strings.HasPrefix(prop, kind.prefix+":") {
+				ogMedia(kind.media, strings.TrimPrefix(prop[len(kind.prefix):], ":"), content)
+				return
+			}
+		}
+	}
+}
+
+//Adds the OpenGraph array property with the structured property sub, which is empty for the root property, to media.
+//The root property and the url property start a new media object, unless the url property repeats the URL of the
+//latest one. Other structured properties apply to the latest media object and are ignored if there is none.
+func ogMedia(media *[]OpenGraphMedia, sub, content string) {
+	if sub == "url" && len(*media) > 0 && (*media)[len(*media)-1].URL == content {
+		return
+	}
+	if sub == "" || sub == "url" {
+		*media = append(*media, OpenGraphMedia{URL: content})
+		return
+	}
+	if len(*media) == 0 {
+		return
+	}
+	o := &(*media)[len(*media)-1]
+	switch sub {
+	case "secure_url":
+		o.SecureURL = content
+	case "type":
+		o.Type = content
+	case "alt":
+		o.Alt = content
+	case "width":
+		o.Width, _ = strconv.Atoi(content)
+	case "height":
+		o.Height, _ = strconv.Atoi(content)
+	}
+}
+
+func (m *PageMetadata) link(e *html.Node) {
+	href, ok := attrValFold(e, "href")
+	if !ok {
+		return
+	}
+	href = strings.Trim(href, asciiWhitespace)
+	rel, _ := attrValFold(e, "rel")
+	rels := strings.Fields(strings.ToLower(rel))
+	for _, r := range rels {
+		switch {
+		case r == "canonical":
+			setFirst(&m.Canonical, href)
+		case r == "alternate":
+			if lang, ok := attrValFold(e, "hreflang"); ok {
+				m.Alternates = append(m.Alternates, Alternate{Hreflang: strings.Trim(lang, asciiWhitespace), Href: href})
+			}
+			typ, _ := attrValFold(e, "type")
+			typ = strings.ToLower(strings.Trim(typ, asciiWhitespace))
+			if feedTypes[typ] {
+				title, _ := attrValFold(e, "title")
+				m.Feeds = append(m.Feeds, Feed{Type: typ, Title: title, Href: href})
+			}
+		case iconRels[r]:
+			sizes, _ := attrValFold(e, "sizes")
+			typ, _ := attrValFold(e, "type")
+			m.Icons = append(m.Icons, Icon{Rel: strings.Join(rels, " "), Href: href, Sizes: sizes, Type: typ})
+			return
+		}
+	}
+}
+
+func setFirst(field *string, val string) {
+	if *field == "" {
+		*field = val
+	}
+}
+
+//Splits s at sep and returns the trimmed non-empty parts, or nil if there are none.
+func splitList(s, sep string) []string {
+	var parts []string
+	for _, p := range strings.Split(s, sep) {
+		if p = strings.Trim(p, asciiWhitespace); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
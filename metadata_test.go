@@ -0,0 +1,92 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html/atom"
+	"reflect"
+	"testing"
+)
+
+func TestMetadata(t *testing.T) {
+	const testDoc = "metadata.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Metadata(root)
+	if Metadata(FirstElementByTag(root, atom.Body)).Title != m.Title {
+		t.Error("Metadata must inspect the whole document")
+	}
+
+	strs := []struct {
+		name, val, expect string
+	}{
+		{"Title", m.Title, "Test file for TestMetadata"},
+		{"Description", m.Description, "A page about things."},
+		{"Canonical", m.Canonical, "https://example.org/page"},
+		{"ThemeColor", m.ThemeColor, "#4285f4"},
+		{"OpenGraph.Title", m.OpenGraph.Title, "OG title"},
+		{"OpenGraph.Type", m.OpenGraph.Type, "article"},
+		{"OpenGraph.Locale", m.OpenGraph.Locale, "en_US"},
+		{"OpenGraph.Description", m.OpenGraph.Description, "OG description by name"},
+		{"Twitter.Card", m.Twitter.Card, "summary_large_image"},
+		{"Twitter.Site", m.Twitter.Site, "@example"},
+		{"Twitter.Image", m.Twitter.Image, "https://example.org/tw.jpg"},
+		{"HTTPEquiv", m.HTTPEquiv["content-type"], "text/html; charset=utf-8"},
+	}
+	for _, s := range strs {
+		if s.val != s.expect {
+			t.Errorf("Expected %s to be \"%s\", got \"%s\"", s.name, s.expect, s.val)
+		}
+	}
+
+	lists := []struct {
+		name        string
+		val, expect []string
+	}{
+		{"Keywords", m.Keywords, []string{"one", "two", "three"}},
+		{"Robots", m.Robots, []string{"noindex", "nofollow"}},
+		{"OpenGraph.LocaleAlternates", m.OpenGraph.LocaleAlternates, []string{"de_DE", "fr_FR"}},
+		{"Names[description]", m.Names["description"], []string{"A page about things.", "Second description"}},
+		{"Names[title]", m.Names["title"], []string{"OG title"}},
+		{"Properties[article:tag]", m.Properties["article:tag"], []string{"tag1", "tag2"}},
+	}
+	for _, l := range lists {
+		if !reflect.DeepEqual(l.val, l.expect) {
+			t.Errorf("Expected %s to be %q, got %q", l.name, l.expect, l.val)
+		}
+	}
+
+	images := []OpenGraphMedia{
+		{URL: "https://example.org/1.jpg", Width: 400, Height: 300, Alt: "First image"},
+		{URL: "https://example.org/2.jpg", SecureURL: "https://secure.example.org/2.jpg"},
+	}
+	if !reflect.DeepEqual(m.OpenGraph.Images, images) {
+		t.Errorf("Expected images %v, got %v", images, m.OpenGraph.Images)
+	}
+	if len(m.OpenGraph.Videos) != 1 || m.OpenGraph.Videos[0].Type != "video/mp4" || m.OpenGraph.Audio != nil {
+		t.Errorf("Unexpected media: %v %v", m.OpenGraph.Videos, m.OpenGraph.Audio)
+	}
+
+	alternates := []Alternate{{"de", "https://example.org/de/page"}, {"x-default", "https://example.org/page"}}
+	if !reflect.DeepEqual(m.Alternates, alternates) {
+		t.Errorf("Expected alternates %v, got %v", alternates, m.Alternates)
+	}
+	feeds := []Feed{{"application/rss+xml", "RSS", "/feed.rss"}, {"application/atom+xml", "", "/feed.atom"}}
+	if !reflect.DeepEqual(m.Feeds, feeds) {
+		t.Errorf("Expected feeds %v, got %v", feeds, m.Feeds)
+	}
+	icons := []Icon{
+		{Rel: "shortcut icon", Href: "/favicon.ico"},
+		{Rel: "apple-touch-icon", Href: "/apple.png", Sizes: "180x180"},
+		{Rel: "icon", Href: "/icon.svg", Type: "image/svg+xml"},
+	}
+	if !reflect.DeepEqual(m.Icons, icons) {
+		t.Errorf("Expected icons %v, got %v", icons, m.Icons)
+	}
+	if _, ok := m.Names["twitter:site"]; ok {
+		t.Error("Property attributes must not be reported as names")
+	}
+}
@@ -0,0 +1,198 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"encoding/json"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//MicrodataItem is an item of the HTML microdata model.
+//Property values are strings, or *MicrodataItem for properties that are items themselves.
+//An item that is a property of itself, directly or indirectly, is replaced by the string "ERROR" as in the HTML standard's JSON conversion.
+//Marshaling an item as JSON yields the item format of the HTML standard.
+type MicrodataItem struct {
+	Node       *html.Node       `json:"-"`              //the element with the itemscope attribute
+	Type       []string         `json:"type,omitempty"` //the tokens of the itemtype attribute
+	ID         string           `json:"id,omitempty"`   //the resolved itemid attribute, only set for typed items
+	Properties map[string][]any `json:"properties"`     //the property values by name in tree order
+}
+
+//Returns the first value of the property name if it is a string, otherwise an empty string.
+func (item *MicrodataItem) String(name string) string {
+	if v := item.Properties[name]; len(v) > 0 {
+		s, _ := v[0].(string)
+		return s
+	}
+	return ""
+}
+
+//Returns the first value of the property name if it is an item, otherwise nil.
+func (item *MicrodataItem) Item(name string) *MicrodataItem {
+	if v := item.Properties[name]; len(v) > 0 {
+		i, _ := v[0].(*MicrodataItem)
+		return i
+	}
+	return nil
+}
+
+//Returns the top-level microdata items in the subtree of doc, in tree order. Properties are collected as defined by the
+//HTML standard, including elements referenced by itemref. URL property values and item IDs are resolved against the
+//document's base URL as returned by BaseURL. Returns nil if doc contains no items.
+func Microdata(doc *html.Node, pageURL *url.URL) []*MicrodataItem {
+	p := &microdataParser{
+		base:  BaseURL(doc, pageURL),
+		order: make(map[*html.Node]int),
+		ids:   make(map[string]*html.Node),
+	}
+	i := 0
+	for n := range Descendants(treeRoot(doc)) {
+		p.order[n] = i
+		i++
+		if id, ok := attrValFold(n, "id"); ok && n.Type == html.ElementNode && p.ids[id] == nil {
+			p.ids[id] = n
+		}
+	}
+	var items []*MicrodataItem
+	for _, e := range FindAll(doc, And(Type(html.ElementNode), isTopLevelItem)) {
+		items = append(items, p.item(e, nil))
+	}
+	return items
+}
+
+//Returns the items in the standard JSON format of the HTML standard, an object with an "items" array.
+func MicrodataJSON(items []*MicrodataItem) ([]byte, error) {
+	if items == nil {
+		items = []*MicrodataItem{}
+	}
+	return json.Marshal(struct {
+		Items []*MicrodataItem `json:"items"`
+	}{items})
+}
+
+func isTopLevelItem(n *html.Node) bool {
+	_, scope := attrValFold(n, "itemscope")
+	_, prop := attrValFold(n, "itemprop")
+	return scope && !prop
+}
+
+type microdataParser struct {
+	base  *url.URL
+	order map[*html.Node]int //position of every node in tree order
+	ids   map[string]*html.Node
+}
+
+//Returns the item of the element e. stack holds the elements of the items that contain e as property.
+func (p *microdataParser) item(e *html.Node, stack []*html.Node) *MicrodataItem {
+	item := &MicrodataItem{
+		Node:       e,
+		Properties: make(map[string][]any),
+	}
+	if v, ok := attrValFold(e, "itemtype"); ok {
+		item.Type = strings.Fields(v)
+	}
+	if v, ok := attrValFold(e, "itemid"); ok && len(item.Type) > 0 {
+		item.ID = p.url(v)
+	}
+	stack = append(stack, e)
+	for _, prop := range p.properties(e) {
+		names, _ := attrValFold(prop, "itemprop")
+		var val any
+		if _, ok := attrValFold(prop, "itemscope"); ok {
+			val = "ERROR"
+			if !slices.Contains(stack, prop) {
+				val = p.item(prop, stack)
+			}
+		} else {
+			val = p.value(prop)
+		}
+		seen := make(map[string]bool)
+		for _, name := range strings.Fields(names) {
+			if !seen[name] {
+				seen[name] = true
+				item.Properties[name] = append(item.Properties[name], val)
+			}
+		}
+	}
+	return item
+}
+
+//Returns the property elements of the item root in tree order, following the crawl of the HTML standard.
+func (p *microdataParser) properties(root *html.Node) []*html.Node {
+	var results, pending []*html.Node
+	memory := map[*html.Node]bool{root: true}
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		pending = append(pending, c)
+	}
+	if refs, ok := attrValFold(root, "itemref"); ok {
+		for _, id := range strings.Fields(refs) {
+			if e := p.ids[id]; e != nil {
+				pending = append(pending, e)
+			}
+		}
+	}
+	for len(pending) > 0 {
+		n := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if memory[n] || n.Type != html.ElementNode {
+			continue
+		}
+		memory[n] = true
+		if _, ok := attrValFold(n, "itemscope"); !ok {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				pending = append(pending, c)
+			}
+		}
+		if v, ok := attrValFold(n, "itemprop"); ok && strings.Trim(v, asciiWhitespace) != "" {
+			results = append(results, n)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return p.order[results[i]] < p.order[results[j]]
+	})
+	return results
+}
+
+//Returns the property value of the element e, which has no itemscope attribute.
+func (p *microdataParser) value(e *html.Node) string {
+	attr := func(key string) string {
+		v, _ := attrValFold(e, key)
+		return v
+	}
+	if isHTMLElement(e) {
+		switch e.DataAtom {
+		case atom.Meta:
+			return attr("content")
+		case atom.Audio, atom.Embed, atom.Iframe, atom.Img, atom.Source, atom.Track, atom.Video:
+			return p.url(attr("src"))
+		case atom.A, atom.Area, atom.Link:
+			return p.url(attr("href"))
+		case atom.Object:
+			return p.url(attr("data"))
+		case atom.Data, atom.Meter:
+			return attr("value")
+		case atom.Time:
+			if v, ok := attrValFold(e, "datetime"); ok {
+				return v
+			}
+		}
+	}
+	return TextContent(e)
+}
+
+//Returns the absolute URL of the attribute value v, or an empty string if v is not a valid URL.
+func (p *microdataParser) url(v string) string {
+	u, err := parseURLAttr(v)
+	if err != nil {
+		return ""
+	}
+	if p.base != nil {
+		u = p.base.ResolveReference(u)
+	}
+	return u.String()
+}
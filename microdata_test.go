@@ -0,0 +1,83 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMicrodata(t *testing.T) {
+	const testDoc = "microdata.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items := Microdata(root, nil)
+	if len(items) != 3 {
+		t.Fatalf("Expected 3 top-level items, got %d", len(items))
+	}
+	p := items[0]
+	if p.Node != ElementByID(root, "product") || p.ID != "https://example.org/shop/widget" || !reflect.DeepEqual(p.Type, []string{"https://schema.org/Product"}) {
+		t.Errorf("Unexpected item %s %v", p.ID, p.Type)
+	}
+	tests := []struct {
+		name, expect string
+	}{
+		{"name", "Widget"},
+		{"image", "https://example.org/shop/widget.jpg"},
+		{"url", "https://example.org/widget"},
+		{"sameAs", "https://example.org/widget"},
+		{"sku", "W-1"},
+		{"gtin", "0123"},
+		{"ratio", "0.5"},
+		{"releaseDate", "2021-03-01"},
+		{"updated", "yesterday"},
+		{"description", "A useful thing"},
+		{"brand", "ACME"},
+	}
+	for _, test := range tests {
+		if s := p.String(test.name); s != test.expect {
+			t.Errorf("Expected property %s to be \"%s\", got \"%s\"", test.name, test.expect, s)
+		}
+		if len(p.Properties[test.name]) != 1 {
+			t.Errorf("Expected one value for property %s, got %d", test.name, len(p.Properties[test.name]))
+		}
+	}
+	if len(p.Properties) != len(tests)+1 {
+		t.Errorf("Expected %d properties, got %d", len(tests)+1, len(p.Properties))
+	}
+	offer := p.Item("offers")
+	if offer == nil || offer.String("price") != "9.99" || offer.String("availability") != "https://schema.org/InStock" {
+		t.Errorf("Unexpected offer %v", offer)
+	}
+
+	loop := items[1]
+	self := loop.Item("self")
+	if self == nil || self.Item("inner") == nil || self.Item("inner").String("self") != "ERROR" {
+		t.Error("Item cycles must be replaced by ERROR")
+	}
+	if items[2].ID != "" {
+		t.Error("Untyped items must not have an ID")
+	}
+}
+
+func TestMicrodataJSON(t *testing.T) {
+	const testDoc = "microdata.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := MicrodataJSON(Microdata(ElementByID(root, "extra"), nil))
+	if err != nil || string(b) != `{"items":[]}` {
+		t.Errorf("Expected an empty item list, got %s %v", b, err)
+	}
+	b, err = MicrodataJSON(Microdata(root, nil)[1:])
+	expect := `{"items":[{"properties":{"name":["Loop"],"self":[{"properties":{"inner":[{"properties":{"self":["ERROR"]}}]}}]}},{"properties":{"a":["1"]}}]}`
+	if err != nil || string(b) != expect {
+		t.Errorf("Expected %s, got %s %v", expect, b, err)
+	}
+}
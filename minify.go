@@ -0,0 +1,277 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"slices"
+	"strings"
+)
+
+//MinifyOptions configures Minify, the zero value selects the smallest output.
+type MinifyOptions struct {
+	KeepConditionalComments bool //keep comments like <!--[if IE]>...<![endif]-->, all other comments are removed
+	KeepEndTags             bool //write end tags that are optional, like the ones of li or p elements
+	KeepDefaultAttrs        bool //keep attributes that are set to their default value, like type="text/javascript"
+	KeepBooleanValues       bool //keep the values of boolean attributes like disabled="disabled"
+}
+
+//Attributes of HTML elements that can be removed if they have the given value, which is compared case-insensitively.
+var defaultAttrs = map[atom.Atom]map[string]string{
+	atom.Area:     {"shape": "rect"},
+	atom.Button:   {"type": "submit"},
+	atom.Col:      {"span": "1"},
+	atom.Colgroup: {"span": "1"},
+	atom.Form:     {"autocomplete": "on", "enctype": "application/x-www-form-urlencoded", "method": "get"},
+	atom.Input:    {"type": "text"},
+	atom.Link:     {"media": "all", "type": "text/css"},
+	atom.Ol:       {"type": "1"},
+	atom.Script:   {"language": "javascript", "type": "text/javascript"},
+	atom.Style:    {"media": "all", "type": "text/css"},
+	atom.Td:       {"colspan": "1", "rowspan": "1"},
+	atom.Textarea: {"wrap": "soft"},
+	atom.Th:       {"colspan": "1", "rowspan": "1"},
+	atom.Track:    {"kind": "subtitles"},
+}
+
+//Boolean attributes of HTML elements, their value is irrelevant.
+var booleanAttrs = map[string]bool{
+	"allowfullscreen": true,
+	"async":           true,
+	"autofocus":       true,
+	"autoplay":        true,
+	"checked":         true,
+	"controls":        true,
+	"default":         true,
+	"defer":           true,
+	"disabled":        true,
+	"formnovalidate":  true,
+	"inert":           true,
+	"ismap":           true,
+	"itemscope":       true,
+	"loop":            true,
+	"multiple":        true,
+	"muted":           true,
+	"nomodule":        true,
+	"novalidate":      true,
+	"open":            true,
+	"playsinline":     true,
+	"readonly":        true,
+	"required":        true,
+	"reversed":        true,
+	"selected":        true,
+}
+
+//Inline elements that are displayed as a box, even if they are empty. Whitespace around them is significant.
+var replacedElements = map[atom.Atom]bool{
+	atom.Audio:    true,
+	atom.Button:   true,
+	atom.Canvas:   true,
+	atom.Embed:    true,
+	atom.Iframe:   true,
+	atom.Img:      true,
+	atom.Input:    true,
+	atom.Meter:    true,
+	atom.Object:   true,
+	atom.Progress: true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Video:    true,
+}
+
+//Returns the HTML of n and its descendants with everything removed that does not change the parsed document,
+//as configured by opts, which may be nil. n is not modified.
+//Whitespace in text is collapsed to single spaces and removed at the start and end of block elements like div, p, li or tr
+//and after other whitespace, as the rendering rules of HTML do by default. Text in pre, textarea, script, style and
+//foreign elements like svg is left untouched. Comments, optional end tags and attributes with default values are removed,
+//values of boolean attributes are shortened, e.g. disabled="disabled" becomes disabled. Attribute values are only quoted
+//if necessary. Minify is built on Render and recurses like it, its stack use grows with the nesting depth of n.
+func Minify(n *html.Node, opts *MinifyOptions) string {
+	m := &minifier{}
+	if opts != nil {
+		m.opts = *opts
+	}
+	root := cloneTree(n)
+	pre := false
+	for a := n.Parent; a != nil; a = a.Parent {
+		pre = pre || preservesWhitespace(a)
+	}
+	if root.Type == html.DocumentNode {
+		m.boundary()
+		m.children(root, pre)
+		m.boundary()
+	} else {
+		m.node(root, pre)
+	}
+	var b strings.Builder
+	//writing to a strings.Builder does not fail
+	Render(&b, root, &RenderOptions{Quote: MinimalQuotes, OmitEndTags: !m.opts.KeepEndTags})
+	return b.String()
+}
+
+//minifier removes insignificant content from a tree.
+type minifier struct {
+	opts  MinifyOptions
+	space bool       //leading whitespace of the next text is insignificant
+	last  *html.Node //the last text node if it ends with a space that becomes insignificant if a block boundary follows
+}
+
+func (m *minifier) children(n *html.Node, pre bool) {
+	var next *html.Node
+	for c := n.FirstChild; c != nil; c = next {
+		next = c.NextSibling
+		m.node(c, pre)
+	}
+}
+
+//Minifies n, pre is true if n is inside an element that preserves whitespace.
+func (m *minifier) node(n *html.Node, pre bool) {
+	switch n.Type {
+	case html.CommentNode:
+		if m.opts.KeepConditionalComments && conditionalComment(n.Data) {
+			m.content()
+			return
+		}
+		Detach(n)
+	case html.TextNode:
+		if pre {
+			m.content()
+			return
+		}
+		m.text(n)
+	case html.ElementNode:
+		m.attrs(n)
+		block := minifyBlock(n)
+		replaced := isHTMLElement(n) && (replacedElements[n.DataAtom] || voidElements[n.DataAtom])
+		switch {
+		case block:
+			m.boundary()
+		case replaced:
+			m.content()
+		}
+		childPre := pre || preservesWhitespace(n)
+		m.children(n, childPre)
+		switch {
+		case block:
+			m.boundary()
+		case replaced || childPre && !pre:
+			m.content()
+		}
+	}
+}
+
+//Collapses the whitespace of the text node n and removes it if nothing is left.
+func (m *minifier) text(n *html.Node) {
+	s := collapseWhitespace(n.Data)
+	if m.space {
+		s = strings.TrimLeft(s, " ")
+	}
+	if s == "" {
+		Detach(n)
+		return
+	}
+	n.Data = s
+	m.space = strings.HasSuffix(s, " ")
+	m.last = nil
+	if m.space {
+		m.last = n
+	}
+}
+
+//Trims the trailing space of the preceding text at the start or end of a block.
+func (m *minifier) boundary() {
+	if m.last != nil {
+		m.last.Data = strings.TrimRight(m.last.Data, " ")
+		if m.last.Data == "" {
+			Detach(m.last)
+		}
+	}
+	m.last = nil
+	m.space = true
+}
+
+//Marks that visible content was written, which makes surrounding whitespace significant.
+func (m *minifier) content() {
+	m.last = nil
+	m.space = false
+}
+
+//Removes default attributes and shortens boolean attributes of n.
+func (m *minifier) attrs(n *html.Node) {
+	if !isHTMLElement(n) {
+		return
+	}
+	defaults := defaultAttrs[n.DataAtom]
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		if a.Namespace == "" {
+			if def, ok := defaults[a.Key]; ok && !m.opts.KeepDefaultAttrs && strings.EqualFold(strings.Trim(a.Val, asciiWhitespace), def) {
+				continue
+			}
+			if booleanAttrs[a.Key] && !m.opts.KeepBooleanValues && strings.EqualFold(a.Val, a.Key) {
+				a.Val = ""
+			}
+		}
+		attrs = append(attrs, a)
+	}
+	n.Attr = attrs
+}
+
+//Reports whether whitespace at the start and end of n and around it is insignificant, because n is laid out as a block
+//or is a line break. Unlike for Render, option and optgroup are no blocks, as they are part of an inline select element.
+func minifyBlock(n *html.Node) bool {
+	if !isHTMLElement(n) {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		return true
+	case atom.Option, atom.Optgroup:
+		return false
+	}
+	return isLayoutBlock(n)
+}
+
+//Reports whether the whitespace in the content of n is significant or n is not an HTML element.
+func preservesWhitespace(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	return !isHTMLElement(n) || preformattedElements[n.DataAtom] || rawTextElements[n.DataAtom]
+}
+
+//Reports whether the comment data belongs to a conditional comment of Internet Explorer, like <!--[if IE]>...<![endif]-->
+//or the parts of a downlevel-revealed one, <!--[if !IE]><!--> and <!--<![endif]-->.
+func conditionalComment(data string) bool {
+	return strings.HasPrefix(data, "[if") || data == "<![endif]"
+}
+
+//Returns a deep copy of n without parent and siblings.
+func cloneTree(n *html.Node) *html.Node {
+	clone := func(n *html.Node) *html.Node {
+		return &html.Node{Type: n.Type, DataAtom: n.DataAtom, Data: n.Data, Namespace: n.Namespace, Attr: slices.Clone(n.Attr)}
+	}
+	root := clone(n)
+	src, dst := n, root
+	for {
+		if src.FirstChild != nil {
+			src = src.FirstChild
+			c := clone(src)
+			dst.AppendChild(c)
+			dst = c
+			continue
+		}
+		//leave src and every ancestor whose last child src is
+		for src != n && src.NextSibling == nil {
+			src, dst = src.Parent, dst.Parent
+		}
+		if src == n {
+			return root
+		}
+		src = src.NextSibling
+		c := clone(src)
+		dst.Parent.AppendChild(c)
+		dst = c
+	}
+}
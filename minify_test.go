@@ -0,0 +1,120 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	for _, name := range []string{"minify.html", "render.html", "text.html", "table.html", "form.html", "markdown.html"} {
+		root, err := parseTestFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := renderString(t, root)
+		for _, opts := range []*MinifyOptions{nil, {KeepConditionalComments: true, KeepEndTags: true, KeepDefaultAttrs: true, KeepBooleanValues: true}} {
+			s := Minify(root, opts)
+			if renderString(t, root) != before {
+				t.Fatalf("%s: Minify must not modify the tree", name)
+			}
+			if len(s) >= len(before) {
+				t.Errorf("%s: minified output is not smaller than the input", name)
+			}
+			//round trip
+			minified, err := html.Parse(strings.NewReader(s))
+			if err != nil {
+				t.Fatal(err)
+			}
+			a, b := collectElements(root), collectElements(minified)
+			if len(a) != len(b) {
+				t.Errorf("%s: expected %d elements, got %d:\n%s", name, len(a), len(b), s)
+				continue
+			}
+			for i := range a {
+				if a[i].Namespace != b[i].Namespace || a[i].Data != b[i].Data {
+					t.Errorf("%s: element %d: expected %s, got %s", name, i, a[i].Data, b[i].Data)
+					break
+				}
+				if opts != nil && !slices.Equal(a[i].Attr, b[i].Attr) {
+					t.Errorf("%s: <%s>: expected attributes %v, got %v", name, a[i].Data, a[i].Attr, b[i].Attr)
+				}
+				if preservesWhitespace(a[i]) && TextContent(a[i]) != TextContent(b[i]) {
+					t.Errorf("%s: <%s>: expected content %q, got %q", name, a[i].Data, TextContent(a[i]), TextContent(b[i]))
+				}
+			}
+			if x, y := InnerText(FirstElementByTag(root, atom.Body)), InnerText(FirstElementByTag(minified, atom.Body)); x != y {
+				t.Errorf("%s: rendered text changed from %q to %q", name, x, y)
+			}
+		}
+	}
+}
+
+func collectElements(n *html.Node) []*html.Node {
+	return FindAll(n, Type(html.ElementNode))
+}
+
+func TestMinifyOptions(t *testing.T) {
+	tests := []struct {
+		src    string
+		opts   *MinifyOptions
+		expect string
+	}{
+		{"<p> a  <b> b </b> c </p> <p>\nd<br> e</p>", nil, "<p>a <b>b </b>c<p>d<br>e"},
+		{"<div>a <!-- c --> b</div>", nil, "<div>a b</div>"},
+		{"<div><!--[if IE]>x<![endif]--><!--[if !IE]><!-->y<!--<![endif]--><!-- c --></div>", &MinifyOptions{KeepConditionalComments: true},
+			"<div><!--[if IE]>x<![endif]--><!--[if !IE]><!-->y<!--<![endif]--></div>"},
+		{"<input TYPE=Text value='' disabled=Disabled checked=false>", nil, "<input value disabled checked=false>"},
+		{"<input type=text disabled=disabled>", &MinifyOptions{KeepDefaultAttrs: true, KeepBooleanValues: true}, "<input type=text disabled=disabled>"},
+		{"<a href='/a b' title=\"it's\" data-x=\"a&quot;\">x</a>", nil, "<a href=\"/a b\" title=it&#39;s data-x=a&#34;>x</a>"},
+		{"<ul><li>a</li> <li>b</li></ul><p>c</p>text", nil, "<ul><li>a<li>b</ul><p>c</p>text"},
+		{"<a><p>a</p></a><video><p>b</p></video>", nil, "<a><p>a</p></a><video><p>b</p></video>"},
+		{"<my-el><p>a</p></my-el><div><p>b</p></div>", nil, "<my-el><p>a</p></my-el><div><p>b</div>"},
+		{"<select><optgroup><option>a</option></optgroup><optgroup><option>b</option></optgroup></select>", nil, "<select><optgroup><option>a<optgroup><option>b</select>"},
+		{"<p>Pick <select><option>a</option></select> now</p>", nil, "<p>Pick <select><option>a</select> now"},
+		{"<p><video src=x></video> caption</p>", nil, "<p><video src=x></video> caption"},
+		{"<script> a  b </script><textarea> a  b </textarea><pre> a <b> b </b></pre>", nil, "<script> a  b </script><textarea> a  b </textarea><pre> a <b> b </b></pre>"},
+	}
+	for _, test := range tests {
+		nodes, err := ParseFragment(nil, test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := El("body", WithChildren(nodes...))
+		//the end tag of body is optional as nothing follows it
+		s := Minify(body, test.opts)
+		if expect := "<body>" + test.expect; s != expect {
+			t.Errorf("%q: expected %s, got %s", test.src, expect, s)
+		}
+	}
+
+	//round trip, whitespace around inline replaced elements is significant
+	for _, src := range []string{
+		"<p>Pick <select><option>a</option></select> now</p>",
+		"<p><video src=x></video> caption</p>",
+		"<p>a <button> b </button> <canvas></canvas> c<object></object> d</p>",
+	} {
+		doc, err := html.Parse(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := Minify(doc, nil)
+		minified, err := html.Parse(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		x := collapseWhitespace(TextContent(FirstElementByTag(doc, atom.P)))
+		if y := collapseWhitespace(TextContent(FirstElementByTag(minified, atom.P))); x != y {
+			t.Errorf("%q: expected text %q, got %q from %s", src, x, y, s)
+		}
+	}
+
+	//the end tag of an element without parent is kept
+	if s := Minify(El("li", Text(" a ")), nil); s != "<li>a</li>" {
+		t.Errorf("Unexpected minified list item %s", s)
+	}
+}
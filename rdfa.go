@@ -0,0 +1,324 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strings"
+)
+
+const rdfType = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+//Triple is an RDF statement extracted by RDFa.
+type Triple struct {
+	Subject   string //an IRI or a blank node identifier starting with "_:"
+	Predicate string //an IRI
+	Object    string //an IRI, a blank node identifier or the value of a literal
+	Literal   bool   //true if Object is a literal
+	Lang      string //the language of a literal, as given by the lang attribute
+}
+
+//Prefixes of the RDFa initial context that are commonly used in HTML documents.
+var rdfaPrefixes = map[string]string{
+	"cc":      "http://creativecommons.org/ns#",
+	"dc":      "http://purl.org/dc/terms/",
+	"dcterms": "http://purl.org/dc/terms/",
+	"foaf":    "http://xmlns.com/foaf/0.1/",
+	"og":      "http://ogp.me/ns#",
+	"rdf":     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfs":    "http://www.w3.org/2000/01/rdf-schema#",
+	"schema":  "http://schema.org/",
+	"sioc":    "http://rdfs.org/sioc/ns#",
+	"skos":    "http://www.w3.org/2004/02/skos/core#",
+	"xsd":     "http://www.w3.org/2001/XMLSchema#",
+}
+
+//Returns the triples described by the RDFa Lite attributes vocab, typeof, property, resource and prefix in the subtree of doc,
+//in document order. Property values are taken from the content, resource, href, src and datetime attributes or the text of the element.
+//Terms are expanded using the vocabulary in scope, CURIEs using the prefix attributes in scope and the common prefixes of the
+//RDFa initial context like schema: or og:. Terms and CURIEs that cannot be expanded are ignored. Relative IRIs are resolved against the
+//document's base URL as returned by BaseURL. Returns nil if no triples were found.
+func RDFa(doc *html.Node, pageURL *url.URL) []Triple {
+	p := &rdfaParser{}
+	base := BaseURL(doc, pageURL)
+	if base != nil {
+		p.base = base
+		p.baseIRI = base.String()
+	}
+	stack := []rdfaContext{{prefixes: rdfaPrefixes, parentObject: p.baseIRI}}
+	for a := doc.Parent; a != nil; a = a.Parent {
+		if lang, ok := attrValFold(a, "lang"); ok && a.Type == html.ElementNode {
+			stack[0].lang = lang
+			break
+		}
+	}
+	Walk(doc, func(n *html.Node) WalkAction {
+		if n.Type == html.ElementNode {
+			stack = append(stack, p.element(n, stack[len(stack)-1]))
+		}
+		return Continue
+	}, func(n *html.Node) WalkAction {
+		if n.Type == html.ElementNode {
+			stack = stack[:len(stack)-1]
+		}
+		return Continue
+	})
+	return p.triples
+}
+
+//Returns the nested view of triples as JSON-LD like objects. Each object has an "@type" array if it is typed, an "@id" if its
+//subject is an IRI and its predicates as keys. The values of a predicate are a single value or an array of values if there are
+//several. Literals are strings, IRIs are objects with an "@id" key, unless they are the subject of further triples,
+//in which case the nested object is inserted. Subjects that are not the object of any triple become the top level objects.
+//Returns nil if triples is empty.
+func RDFaObjects(triples []Triple) []map[string]any {
+	var subjects []string
+	bySubject := make(map[string][]Triple)
+	referenced := make(map[string]bool)
+	for _, t := range triples {
+		if _, ok := bySubject[t.Subject]; !ok {
+			subjects = append(subjects, t.Subject)
+		}
+		bySubject[t.Subject] = append(bySubject[t.Subject], t)
+		if !t.Literal && t.Predicate != rdfType {
+			referenced[t.Object] = true
+		}
+	}
+	visited := make(map[string]bool)
+	var nest func(subject string) map[string]any
+	nest = func(subject string) map[string]any {
+		visited[subject] = true
+		obj := make(map[string]any)
+		if !strings.HasPrefix(subject, "_:") {
+			obj["@id"] = subject
+		}
+		for _, t := range bySubject[subject] {
+			var val any
+			switch {
+			case t.Predicate == rdfType:
+				types, _ := obj["@type"].([]any)
+				obj["@type"] = append(types, t.Object)
+				continue
+			case t.Literal:
+				val = t.Object
+			case bySubject[t.Object] != nil && !visited[t.Object]:
+				val = nest(t.Object)
+			default:
+				val = map[string]any{"@id": t.Object}
+			}
+			switch v := obj[t.Predicate].(type) {
+			case nil:
+				obj[t.Predicate] = val
+			case []any:
+				obj[t.Predicate] = append(v, val)
+			default:
+				obj[t.Predicate] = []any{v, val}
+			}
+		}
+		return obj
+	}
+	var objects []map[string]any
+	for _, s := range subjects {
+		if !referenced[s] && !visited[s] {
+			objects = append(objects, nest(s))
+		}
+	}
+	//subjects that only occur in cycles
+	for _, s := range subjects {
+		if !visited[s] {
+			objects = append(objects, nest(s))
+		}
+	}
+	return objects
+}
+
+type rdfaParser struct {
+	base    *url.URL
+	baseIRI string
+	bnodes  int
+	triples []Triple
+}
+
+//rdfaContext is the evaluation context an element passes to its children.
+type rdfaContext struct {
+	vocab        string
+	prefixes     map[string]string
+	lang         string
+	parentObject string
+}
+
+//Processes the RDFa attributes of the element e and returns the context for its children.
+func (p *rdfaParser) element(e *html.Node, ctx rdfaContext) rdfaContext {
+	attr := func(key string) (string, bool) {
+		return attrValFold(e, key)
+	}
+	if v, ok := attr("vocab"); ok {
+		ctx.vocab = ""
+		if v = strings.Trim(v, asciiWhitespace); v != "" {
+			ctx.vocab = p.resolve(v)
+		}
+	}
+	if v, ok := attr("prefix"); ok {
+		ctx.prefixes = parseRDFaPrefixes(v, ctx.prefixes)
+	}
+	if v, ok := attr("lang"); ok {
+		ctx.lang = v
+	}
+
+	//the first of resource, href and src
+	var resource string
+	hasResource := false
+	for _, key := range []string{"resource", "href", "src"} {
+		if v, ok := attr(key); ok {
+			if key == "resource" {
+				resource = p.resourceIRI(v, ctx)
+			} else {
+				resource = p.resolve(v)
+			}
+			hasResource = true
+			break
+		}
+	}
+	property, hasProperty := attr("property")
+	typeOf, hasTypeOf := attr("typeof")
+	_, hasContent := attr("content")
+	isRoot := e.Parent != nil && e.Parent.Type == html.DocumentNode
+
+	newSubject := ctx.parentObject
+	var typedResource, objectResource string
+	if hasProperty && !hasContent {
+		if hasTypeOf {
+			switch {
+			case hasResource:
+				typedResource = resource
+			default:
+				typedResource = p.bnode()
+			}
+			objectResource = typedResource
+		}
+	} else {
+		switch {
+		case hasResource:
+			newSubject = resource
+		case isRoot:
+			newSubject = p.baseIRI
+		case hasTypeOf:
+			newSubject = p.bnode()
+		}
+		if hasTypeOf {
+			typedResource = newSubject
+		}
+	}
+	if hasTypeOf && typedResource != "" {
+		for _, t := range p.terms(typeOf, ctx) {
+			p.triples = append(p.triples, Triple{Subject: typedResource, Predicate: rdfType, Object: t})
+		}
+	}
+
+	if hasProperty {
+		obj := Triple{Subject: newSubject}
+		content, _ := attr("content")
+		datetime, hasDatetime := attr("datetime")
+		switch {
+		case hasContent:
+			obj.Object, obj.Literal = content, true
+		case hasResource:
+			obj.Object = resource
+		case hasTypeOf:
+			obj.Object = typedResource
+		case isHTMLElement(e) && e.DataAtom == atom.Time && hasDatetime:
+			obj.Object, obj.Literal = datetime, true
+		default:
+			obj.Object, obj.Literal = TextContent(e), true
+		}
+		if obj.Literal {
+			obj.Lang = ctx.lang
+		}
+		for _, pred := range p.terms(property, ctx) {
+			obj.Predicate = pred
+			p.triples = append(p.triples, obj)
+		}
+	}
+
+	switch {
+	case objectResource != "":
+		ctx.parentObject = objectResource
+	default:
+		ctx.parentObject = newSubject
+	}
+	return ctx
+}
+
+//Expands the space-separated terms, CURIEs and absolute IRIs in v. CURIEs with an unknown prefix are ignored,
+//values like https://schema.org/name whose reference starts with // are taken as absolute IRIs.
+func (p *rdfaParser) terms(v string, ctx rdfaContext) []string {
+	var iris []string
+	for _, t := range strings.Fields(v) {
+		if prefix, ref, ok := strings.Cut(t, ":"); ok {
+			if ns, ok := ctx.prefixes[strings.ToLower(prefix)]; ok {
+				iris = append(iris, ns+ref)
+			} else if strings.HasPrefix(ref, "//") {
+				iris = append(iris, t)
+			}
+		} else if ctx.vocab != "" {
+			iris = append(iris, ctx.vocab+t)
+		}
+	}
+	return iris
+}
+
+//Expands the value of a resource attribute, which is a safe CURIE in square brackets, a CURIE or an IRI.
+func (p *rdfaParser) resourceIRI(v string, ctx rdfaContext) string {
+	v = strings.Trim(v, asciiWhitespace)
+	safe := strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]")
+	if safe {
+		v = v[1 : len(v)-1]
+	}
+	if prefix, ref, ok := strings.Cut(v, ":"); ok {
+		if prefix == "_" {
+			return v
+		}
+		if ns, ok := ctx.prefixes[strings.ToLower(prefix)]; ok {
+			return ns + ref
+		}
+	}
+	return p.resolve(v)
+}
+
+//Resolves v against the base IRI, relative IRIs are kept if the base is unknown.
+func (p *rdfaParser) resolve(v string) string {
+	u, err := parseURLAttr(v)
+	if err != nil {
+		return strings.Trim(v, asciiWhitespace)
+	}
+	if p.base != nil {
+		u = p.base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+func (p *rdfaParser) bnode() string {
+	p.bnodes++
+	return fmt.Sprintf("_:b%d", p.bnodes-1)
+}
+
+//Returns a copy of prefixes extended by the mappings of a prefix attribute, which consists of pairs of "prefix:" and IRI.
+func parseRDFaPrefixes(v string, prefixes map[string]string) map[string]string {
+	m := make(map[string]string, len(prefixes))
+	for k, ns := range prefixes {
+		m[k] = ns
+	}
+	fields := strings.Fields(v)
+	for i := 0; i+1 < len(fields); i++ {
+		prefix, ok := strings.CutSuffix(fields[i], ":")
+		if !ok || prefix == "_" {
+			continue
+		}
+		m[strings.ToLower(prefix)] = fields[i+1]
+		i++
+	}
+	return m
+}
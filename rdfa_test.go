@@ -0,0 +1,86 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"encoding/json"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestRDFa(t *testing.T) {
+	const testDoc = "rdfa.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		article = "https://example.org/news/#article"
+		base    = "https://example.org/news/"
+		s       = "https://schema.org/"
+	)
+	expect := []Triple{
+		{base, "http://ogp.me/ns#title", "Page title", true, "en"},
+		{base, "http://purl.org/dc/elements/1.1/title", "Page title", true, "en"},
+		{article, rdfType, s + "NewsArticle", false, ""},
+		{article, s + "headline", "Budget approved", true, "en"},
+		{article, s + "datePublished", "2021-05-01", true, "en"},
+		{"_:b0", rdfType, s + "Person", false, ""},
+		{article, s + "author", "_:b0", false, ""},
+		{"_:b0", s + "name", "Jörg", true, "de"},
+		{"_:b0", s + "url", "https://example.org/people/joerg", false, ""},
+		{"_:b0", s + "sameAs", "https://example.org/people/joerg", false, ""},
+		{article, s + "publisher", "http://example.com/ns#org", false, ""},
+		{article, "http://example.com/ns#custom", "Custom", true, "en"},
+		{"https://example.org/other", rdfType, "http://schema.org/Thing", false, ""},
+		{"https://example.org/other", rdfType, "http://example.com/ns#Other", false, ""},
+	}
+	triples := RDFa(root, nil)
+	if len(triples) != len(expect) {
+		t.Fatalf("Expected %d triples, got %d: %v", len(expect), len(triples), triples)
+	}
+	for i := range triples {
+		if triples[i] != expect[i] {
+			t.Errorf("Triple %d: expected %v, got %v", i, expect[i], triples[i])
+		}
+	}
+	if RDFa(FirstElementByTag(root, atom.Title), nil) != nil {
+		t.Error("Expected no triples")
+	}
+}
+
+func TestRDFaUnknownPrefix(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<p vocab="https://schema.org/" typeof="x:Thing"><span property="x:y name https://example.org/p">v</span></p>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	triples := RDFa(doc, nil)
+	if len(triples) != 2 || triples[0].Predicate != "https://schema.org/name" || triples[1].Predicate != "https://example.org/p" {
+		t.Errorf("CURIEs with an unknown prefix must be ignored, got %v", triples)
+	}
+}
+
+func TestRDFaObjects(t *testing.T) {
+	triples := []Triple{
+		{Subject: "_:b0", Predicate: rdfType, Object: "https://schema.org/Person"},
+		{Subject: "_:b0", Predicate: "https://schema.org/name", Object: "Jörg", Literal: true},
+		{Subject: "_:b0", Predicate: "https://schema.org/knows", Object: "https://example.org/a"},
+		{Subject: "https://example.org/a", Predicate: "https://schema.org/knows", Object: "_:b0"},
+		{Subject: "https://example.org/a", Predicate: "https://schema.org/name", Object: "A", Literal: true},
+		{Subject: "https://example.org/a", Predicate: "https://schema.org/name", Object: "B", Literal: true},
+		{Subject: "_:b1", Predicate: "https://schema.org/url", Object: "https://example.org/u"},
+	}
+	b, err := json.Marshal(RDFaObjects(triples))
+	expect := `[{"https://schema.org/url":{"@id":"https://example.org/u"}},` +
+		`{"@type":["https://schema.org/Person"],"https://schema.org/knows":{"@id":"https://example.org/a","https://schema.org/knows":{"@id":"_:b0"},"https://schema.org/name":["A","B"]},"https://schema.org/name":"Jörg"}]`
+	if err != nil || string(b) != expect {
+		t.Errorf("Expected %s, got %s", expect, b)
+	}
+	if RDFaObjects(nil) != nil {
+		t.Error("Expected nil for no triples")
+	}
+}
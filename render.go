@@ -0,0 +1,421 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"bufio"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"slices"
+	"strings"
+)
+
+//QuoteStyle selects how Render quotes attribute values.
+type QuoteStyle int
+
+const (
+	DoubleQuotes  QuoteStyle = iota //key="val"
+	SingleQuotes                    //key='val'
+	MinimalQuotes                   //key=val if the value needs no quotes, key if it is empty, key="val" otherwise
+)
+
+//RenderOptions configures Render, the zero value renders like html.Render, except that void elements are written as <br>.
+type RenderOptions struct {
+	Indent        string     //indentation of one nesting level, output is only split into lines if Indent is not empty
+	SortAttrs     bool       //write attributes sorted by namespace and key instead of in document order
+	Quote         QuoteStyle //quote character of attribute values
+	SelfClose     bool       //write void elements like <br/> and foreign elements without children like <circle/>
+	StripComments bool       //omit comments
+	OmitEndTags   bool       //omit end tags that the HTML standard marks as optional, like the ones of li elements
+}
+
+//Void elements of the HTML namespace, they have no end tag.
+var voidElements = map[atom.Atom]bool{
+	atom.Area:   true,
+	atom.Base:   true,
+	atom.Br:     true,
+	atom.Col:    true,
+	atom.Embed:  true,
+	atom.Hr:     true,
+	atom.Img:    true,
+	atom.Input:  true,
+	atom.Keygen: true,
+	atom.Link:   true,
+	atom.Meta:   true,
+	atom.Param:  true,
+	atom.Source: true,
+	atom.Track:  true,
+	atom.Wbr:    true,
+}
+
+//Elements of the HTML namespace whose text children are written without escaping.
+var rawTextElements = map[atom.Atom]bool{
+	atom.Iframe:    true,
+	atom.Noembed:   true,
+	atom.Noframes:  true,
+	atom.Noscript:  true,
+	atom.Plaintext: true,
+	atom.Script:    true,
+	atom.Style:     true,
+	atom.Xmp:       true,
+}
+
+//Elements that start on a line of their own when indenting, in addition to the ones in blockElements.
+var layoutBlockElements = map[atom.Atom]bool{
+	atom.Col:      true,
+	atom.Colgroup: true,
+	atom.Tbody:    true,
+	atom.Td:       true,
+	atom.Tfoot:    true,
+	atom.Th:       true,
+	atom.Thead:    true,
+}
+
+//Writes the HTML of n and its descendants to w as configured by opts, which may be nil.
+//If opts.Indent is set, elements that contain block elements like div, p, li or tr get their children on lines of their own,
+//indented by one level. Whitespace-only text next to these children is replaced by the line breaks, whitespace at the start
+//and end of their runs of text and inline elements is trimmed. Those runs and the content of other elements, including
+//pre, textarea, script and foreign elements like svg, are written unchanged on a single line, so the rendered text stays the same.
+//Each line ends with a newline. Like html.Render, Render recurses once per level of nesting, so its stack use grows
+//with the depth of the tree.
+func Render(w io.Writer, n *html.Node, opts *RenderOptions) error {
+	r := &renderer{w: bufio.NewWriter(w)}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Indent == "" {
+		r.node(n)
+	} else {
+		r.lines(n, 0)
+	}
+	return r.w.Flush()
+}
+
+//renderer writes nodes to w, write errors are kept by w and returned by Flush.
+type renderer struct {
+	w    *bufio.Writer
+	opts RenderOptions
+	stop bool //set by a plaintext element, nothing may follow it
+}
+
+//Writes n in compact form.
+func (r *renderer) node(n *html.Node) {
+	if r.stop {
+		return
+	}
+	switch n.Type {
+	case html.DocumentNode:
+		r.children(n)
+	case html.TextNode:
+		if p := n.Parent; p != nil && isHTMLElement(p) && rawTextElements[p.DataAtom] {
+			r.w.WriteString(n.Data)
+		} else {
+			r.w.WriteString(html.EscapeString(n.Data))
+		}
+	case html.CommentNode:
+		if !r.opts.StripComments {
+			r.w.WriteString("<!--" + n.Data + "-->")
+		}
+	case html.DoctypeNode:
+		r.doctype(n)
+	case html.RawNode:
+		r.w.WriteString(n.Data)
+	case html.ElementNode:
+		r.startTag(n)
+		if r.selfClosed(n) {
+			return
+		}
+		r.children(n)
+		if isHTMLElement(n) && n.DataAtom == atom.Plaintext {
+			r.stop = true
+		}
+		if !r.stop {
+			r.endTag(n)
+		}
+	}
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.node(c)
+	}
+}
+
+//Reports whether the element n has no end tag.
+func (r *renderer) selfClosed(n *html.Node) bool {
+	if n.Namespace == "" {
+		return voidElements[n.DataAtom]
+	}
+	return r.opts.SelfClose && n.FirstChild == nil
+}
+
+func (r *renderer) startTag(n *html.Node) {
+	r.w.WriteString("<" + n.Data)
+	attrs := n.Attr
+	if r.opts.SortAttrs {
+		attrs = slices.Clone(attrs)
+		slices.SortStableFunc(attrs, func(a, b html.Attribute) int {
+			if c := strings.Compare(a.Namespace, b.Namespace); c != 0 {
+				return c
+			}
+			return strings.Compare(a.Key, b.Key)
+		})
+	}
+	unquoted := false
+	for _, a := range attrs {
+		r.w.WriteByte(' ')
+		if a.Namespace != "" {
+			r.w.WriteString(a.Namespace + ":")
+		}
+		r.w.WriteString(a.Key)
+		unquoted = r.opts.Quote == MinimalQuotes
+		if unquoted && a.Val == "" {
+			continue
+		}
+		r.w.WriteByte('=')
+		unquoted = r.attrVal(a.Val)
+	}
+	if r.opts.SelfClose && r.selfClosed(n) {
+		//a slash would become part of an unquoted value
+		if unquoted {
+			r.w.WriteByte(' ')
+		}
+		r.w.WriteByte('/')
+	}
+	r.w.WriteByte('>')
+	//the parser drops a newline at the start of these elements
+	if isHTMLElement(n) && (n.DataAtom == atom.Pre || n.DataAtom == atom.Listing || n.DataAtom == atom.Textarea) {
+		if c := n.FirstChild; c != nil && c.Type == html.TextNode && strings.HasPrefix(c.Data, "\n") {
+			r.w.WriteByte('\n')
+		}
+	}
+}
+
+//Writes the attribute value val, returns true if it was written without quotes.
+func (r *renderer) attrVal(val string) bool {
+	val = html.EscapeString(val)
+	quote := byte('"')
+	switch r.opts.Quote {
+	case SingleQuotes:
+		quote = '\''
+	case MinimalQuotes:
+		//the escaped value contains no quotes and angle brackets
+		if val != "" && !strings.ContainsAny(val, asciiWhitespace+"=`") {
+			r.w.WriteString(val)
+			return true
+		}
+	}
+	r.w.WriteByte(quote)
+	r.w.WriteString(val)
+	r.w.WriteByte(quote)
+	return false
+}
+
+func (r *renderer) endTag(n *html.Node) {
+	if !r.opts.OmitEndTags || !optionalEndTag(n) {
+		r.w.WriteString("</" + n.Data + ">")
+	}
+}
+
+//Reports whether the end tag of n may be omitted according to the optional tags section of the HTML standard,
+//given the nodes that follow n. Elements without parent keep their end tag unless it depends on the next sibling only.
+func optionalEndTag(n *html.Node) bool {
+	if !isHTMLElement(n) {
+		return false
+	}
+	next := n.NextSibling
+	last := next == nil && n.Parent != nil
+	followedBy := func(tag ...atom.Atom) bool {
+		return next != nil && isHTMLElement(next) && slices.Contains(tag, next.DataAtom)
+	}
+	switch n.DataAtom {
+	case atom.Html, atom.Body:
+		return next == nil || next.Type != html.CommentNode
+	case atom.Head, atom.Colgroup, atom.Caption:
+		return next == nil || next.Type != html.CommentNode && !(next.Type == html.TextNode && next.Data != "" && strings.IndexByte(asciiWhitespace, next.Data[0]) >= 0)
+	case atom.Li:
+		return last || followedBy(atom.Li)
+	case atom.Dt:
+		return followedBy(atom.Dt, atom.Dd)
+	case atom.Dd:
+		return last || followedBy(atom.Dt, atom.Dd)
+	case atom.Rt, atom.Rp:
+		return last || followedBy(atom.Rt, atom.Rp)
+	case atom.Optgroup:
+		return last || followedBy(atom.Optgroup, atom.Hr)
+	case atom.Option:
+		return last || followedBy(atom.Option, atom.Optgroup, atom.Hr)
+	case atom.Thead:
+		return followedBy(atom.Tbody, atom.Tfoot)
+	case atom.Tbody:
+		return last || followedBy(atom.Tbody, atom.Tfoot)
+	case atom.Tfoot:
+		return last
+	case atom.Tr:
+		return last || followedBy(atom.Tr)
+	case atom.Td, atom.Th:
+		return last || followedBy(atom.Td, atom.Th)
+	case atom.P:
+		//a table does not close a p element in quirks mode, so it is not in this list
+		if followedBy(atom.Address, atom.Article, atom.Aside, atom.Blockquote, atom.Details, atom.Dialog, atom.Div, atom.Dl,
+			atom.Fieldset, atom.Figcaption, atom.Figure, atom.Footer, atom.Form, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5,
+			atom.H6, atom.Header, atom.Hgroup, atom.Hr, atom.Main, atom.Menu, atom.Nav, atom.Ol, atom.P, atom.Pre,
+			atom.Section, atom.Ul) {
+			return true
+		}
+		if !last {
+			return false
+		}
+		p := n.Parent
+		if !isHTMLElement(p) {
+			return p.Type == html.DocumentNode
+		}
+		switch p.DataAtom {
+		case atom.A, atom.Audio, atom.Del, atom.Ins, atom.Map, atom.Noscript, atom.Video:
+			return false
+		}
+		return p.DataAtom != 0 || !strings.Contains(p.Data, "-")
+	}
+	return false
+}
+
+func (r *renderer) doctype(n *html.Node) {
+	r.w.WriteString("<!DOCTYPE " + n.Data)
+	var public, system string
+	for _, a := range n.Attr {
+		switch a.Key {
+		case "public":
+			public = a.Val
+		case "system":
+			system = a.Val
+		}
+	}
+	switch {
+	case public != "":
+		r.w.WriteString(" PUBLIC " + doctypeQuote(public))
+		if system != "" {
+			r.w.WriteString(" " + doctypeQuote(system))
+		}
+	case system != "":
+		r.w.WriteString(" SYSTEM " + doctypeQuote(system))
+	}
+	r.w.WriteByte('>')
+}
+
+//Quotes an identifier of a doctype, which cannot contain escapes.
+func doctypeQuote(s string) string {
+	if strings.Contains(s, `"`) {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+//Writes n as lines indented by depth levels, see Render.
+func (r *renderer) lines(n *html.Node, depth int) {
+	switch {
+	case r.stop:
+	case n.Type == html.DocumentNode:
+		r.blockChildren(n, depth)
+	case n.Type == html.CommentNode && r.opts.StripComments:
+	case r.expandable(n):
+		r.indent(depth)
+		r.startTag(n)
+		r.w.WriteByte('\n')
+		r.blockChildren(n, depth+1)
+		if r.stop {
+			return
+		}
+		if !r.opts.OmitEndTags || !optionalEndTag(n) {
+			r.indent(depth)
+			r.endTag(n)
+			r.w.WriteByte('\n')
+		}
+	default:
+		r.indent(depth)
+		r.node(n)
+		r.w.WriteByte('\n')
+	}
+}
+
+//Writes the children of n, each layout block on a line of its own and each run of other nodes on a single line.
+func (r *renderer) blockChildren(n *html.Node, depth int) {
+	var run []*html.Node
+	flush := func() {
+		for len(run) > 0 && isWhitespaceText(run[0]) {
+			run = run[1:]
+		}
+		for len(run) > 0 && isWhitespaceText(run[len(run)-1]) {
+			run = run[:len(run)-1]
+		}
+		if len(run) == 0 {
+			return
+		}
+		r.indent(depth)
+		for i, c := range run {
+			if c.Type != html.TextNode {
+				r.node(c)
+				continue
+			}
+			s := html.EscapeString(c.Data)
+			if i == 0 {
+				s = strings.TrimLeft(s, asciiWhitespace)
+			}
+			if i == len(run)-1 {
+				s = strings.TrimRight(s, asciiWhitespace)
+			}
+			r.w.WriteString(s)
+		}
+		r.w.WriteByte('\n')
+		run = run[:0]
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c.Type == html.CommentNode && r.opts.StripComments:
+		case isLayoutBlock(c):
+			flush()
+			r.lines(c, depth)
+		default:
+			run = append(run, c)
+		}
+	}
+	flush()
+}
+
+//Reports whether n starts on a line of its own when indenting. Elements of the document head are blocks inside the head only.
+func isLayoutBlock(n *html.Node) bool {
+	switch {
+	case n.Type == html.DoctypeNode:
+		return true
+	case !isHTMLElement(n):
+		return false
+	case blockElements[n.DataAtom] || layoutBlockElements[n.DataAtom] || n.DataAtom == atom.Head:
+		return true
+	}
+	p := n.Parent
+	return hiddenElements[n.DataAtom] && (p == nil || p.Type == html.DocumentNode || isHTMLElement(p) && (p.DataAtom == atom.Head || p.DataAtom == atom.Html))
+}
+
+//Reports whether the children of n can be written on lines of their own, which is the case if one of them is a layout block
+//and n does not preserve whitespace.
+func (r *renderer) expandable(n *html.Node) bool {
+	if !isHTMLElement(n) || preformattedElements[n.DataAtom] || rawTextElements[n.DataAtom] || voidElements[n.DataAtom] {
+		return false
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if isLayoutBlock(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWhitespaceText(n *html.Node) bool {
+	return n.Type == html.TextNode && strings.Trim(n.Data, asciiWhitespace) == ""
+}
+
+func (r *renderer) indent(depth int) {
+	for i := 0; i < depth; i++ {
+		r.w.WriteString(r.opts.Indent)
+	}
+}
@@ -0,0 +1,117 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	const testDoc = "render.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := Render(&b, root, &RenderOptions{Indent: "\t"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "<!DOCTYPE html>\n" +
+		"<html lang=\"en\">\n" +
+		"\t<head>\n" +
+		"\t\t<meta charset=\"utf-8\">\n" +
+		"\t\t<title>Render   test</title>\n" +
+		"\t\t<!-- head comment -->\n" +
+		"\t\t<script>if (a < b && c) { x(\"</p>\") }</script>\n" +
+		"\t</head>\n" +
+		"\t<body class=\"b\" id=\"top\">\n" +
+		"\t\t<div id=\"main\" data-x=\"say &#34;hi&#34;\">\n" +
+		"\t\t\tIntro text <b>bold</b><i>italic</i>\n" +
+		"\t\t\t<p>Para <a href=\"/x?a=1&amp;b=2\">link</a> end.</p>\n" +
+		"\t\t\t<p>Second</p>\n" +
+		"\t\t\t<ul>\n" +
+		"\t\t\t\t<li>one</li>\n" +
+		"\t\t\t\t<li>\n" +
+		"\t\t\t\t\ttwo\n" +
+		"\t\t\t\t\t<ul>\n" +
+		"\t\t\t\t\t\t<li>nested</li>\n" +
+		"\t\t\t\t\t</ul>\n" +
+		"\t\t\t\t</li>\n" +
+		"\t\t\t</ul>\n" +
+		"\t\t\t<pre>  keep   this\n" +
+		"\texactly</pre>\n" +
+		"\t\t\t<textarea> raw  text</textarea><br>\n" +
+		"\t\t\t<table>\n" +
+		"\t\t\t\t<tbody>\n" +
+		"\t\t\t\t\t<tr>\n" +
+		"\t\t\t\t\t\t<td>a</td>\n" +
+		"\t\t\t\t\t\t<td>b</td>\n" +
+		"\t\t\t\t\t</tr>\n" +
+		"\t\t\t\t</tbody>\n" +
+		"\t\t\t</table>\n" +
+		"\t\t\t<svg viewBox=\"0 0 1 1\"><circle r=\"1\"></circle></svg>\n" +
+		"\t\t</div>\n" +
+		"\t</body>\n" +
+		"</html>\n"
+	if b.String() != expect {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expect, b.String())
+	}
+
+	//indenting must not change the rendered text
+	reparsed, err := html.Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := InnerText(FirstElementByTag(root, atom.Body)), InnerText(FirstElementByTag(reparsed, atom.Body)); a != b {
+		t.Errorf("Rendered text changed from %q to %q", a, b)
+	}
+
+	//without options the output equals the one of html.Render, except for void elements
+	for _, name := range []string{testDoc, "text.html", "table.html", "urls.html"} {
+		doc, err := parseTestFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Reset()
+		if err := Render(&b, doc, nil); err != nil {
+			t.Fatal(err)
+		}
+		if expect := strings.ReplaceAll(renderString(t, doc), "/>", ">"); b.String() != expect {
+			t.Errorf("%s: expected %s, got %s", name, expect, b.String())
+		}
+	}
+}
+
+func TestRenderOptions(t *testing.T) {
+	tests := []struct {
+		node   *html.Node
+		opts   RenderOptions
+		expect string
+	}{
+		{El("a", WithAttr("href", "/"), WithAttr("class", "x"), WithAttr("title", `"it's"`)), RenderOptions{SortAttrs: true},
+			`<a class="x" href="/" title="&#34;it&#39;s&#34;"></a>`},
+		{El("a", WithAttr("href", "/"), WithAttr("class", "x")), RenderOptions{Quote: SingleQuotes}, `<a href='/' class='x'></a>`},
+		{El("p", Text("a"), WithChildren(El("br"), CommentNode(" c "), El("img", WithAttr("alt", "")))), RenderOptions{SelfClose: true, StripComments: true},
+			`<p>a<br/><img alt=""/></p>`},
+		{El("svg", WithChildren(El("use", WithAttr("xlink:href", "#i")), El("g"))), RenderOptions{SelfClose: true}, `<svg><use xlink:href="#i"/><g/></svg>`},
+		{El("div", WithChildren(CommentNode("c"), El("p", Text("a")), TextNode(" b "), El("span"))), RenderOptions{Indent: "  "},
+			"<div>\n  <!--c-->\n  <p>a</p>\n  b <span></span>\n</div>\n"},
+		{El("div", WithChildren(CommentNode("c"), El("p"))), RenderOptions{Indent: "  ", StripComments: true}, "<div>\n  <p></p>\n</div>\n"},
+		{El("p", WithChildren(El("span", Text("x")), TextNode(" "), El("b"))), RenderOptions{Indent: "  "}, "<p><span>x</span> <b></b></p>\n"},
+		{El("textarea", Text("\nx")), RenderOptions{Indent: "  "}, "<textarea>\n\nx</textarea>\n"},
+		{El("body", WithChildren(El("plaintext", Text("<b>")), El("p"))), RenderOptions{}, "<body><plaintext><b>"},
+	}
+	for _, test := range tests {
+		var b strings.Builder
+		if err := Render(&b, test.node, &test.opts); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != test.expect {
+			t.Errorf("Expected %q, got %q", test.expect, b.String())
+		}
+	}
+}
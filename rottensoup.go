@@ -25,124 +25,67 @@ func AttrVal(n *html.Node, namespace, key string) string {
 //Executes depth-first search on all child nodes of n, returns the first element found with the given id.
 //If no suitable element was found, nil will be returned.
 func ElementByID(n *html.Node, id string) *html.Node {
-	nodes := make([]*html.Node, 0, 1)
-	attr := html.Attribute{Key: "id", Val: id}
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.MatchAttrs(&nodes, true, attr)), nil)
-	if len(nodes) > 0 {
-		return nodes[0]
-	}
-	return nil
+	return Find(n, Attr(html.Attribute{Key: "id", Val: id}))
 }
 
 //Executes depth-first search on all child nodes of n, returns the first node that matches NodeType t or nil if no such node was found.
 func FirstNodeByType(n *html.Node, t html.NodeType) *html.Node {
-	var match *html.Node
-	pre := func(n *html.Node) bool {
-		match = n
-		return false
-	}
-	nav.DFS(n, cond.TypeFilter(t, pre), nil)
-	return match
+	return Find(n, Type(t))
 }
 
 //Executes depth-first search on all child nodes of n and returns all elements that
 //contain all given attributes. Returns nil if no matches were found.
 func ElementsByAttr(n *html.Node, attr ...html.Attribute) []*html.Node {
-	nodes := make([]*html.Node, 0, 10)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.MatchAttrs(&nodes, false, attr...)), nil)
-	if len(nodes) == 0 {
-		return nil
-	}
-	return nodes
+	return FindAll(n, Attr(attr...))
 }
 
 //Executes depth-first search on all child nodes of n, returns a slice that contains all elements that have an attribute
 //where namespace and key are equal to the function args and where the attribute's val matches the given regular expression.
 //If no proper element was found, nil will be returned.
 func ElementsByAttrMatch(n *html.Node, namespace, key string, val *regexp.Regexp) []*html.Node {
-	nodes := make([]*html.Node, 0, 10)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.AttrValByRegex(&nodes, namespace, key, val)), nil)
-	if len(nodes) == 0 {
-		return nil
-	}
-	return nodes
+	return FindAll(n, AttrRegex(namespace, key, val))
 }
 
 //Executes depth-first search on all child nodes of n and returns the first element that
 //contains all given attributes. Returns nil if no match was found.
 func FirstElementByAttr(n *html.Node, attr ...html.Attribute) *html.Node {
-	nodes := make([]*html.Node, 0, 1)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.MatchAttrs(&nodes, true, attr...)), nil)
-	if len(nodes) < 1 {
-		return nil
-	}
-	return nodes[0]
+	return Find(n, Attr(attr...))
 }
 
 //Executes depth-first search on all child nodes of n and returns the first element that is a member of all given classes.
 //Returns nil if no such element was found.
 func FirstElementByClassName(n *html.Node, name ...string) *html.Node {
-	nodes := make([]*html.Node, 0, 1)
-	nav.DFS(n, cond.MatchClassNames(&nodes, true, name...), nil)
-	if len(nodes) < 1 {
-		return nil
-	}
-	return nodes[0]
+	return Find(n, Class(name...))
 }
 
 //Executes depth-first search on all child nodes of n and returns the first element that matches at least one of the given tags.
 //Returns nil if no such element was found.
 func FirstElementByTag(n *html.Node, tag ...atom.Atom) *html.Node {
-	nodes := make([]*html.Node, 0, 1)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.MatchTag(&nodes, true, tag...)), nil)
-	if len(nodes) > 0 {
-		return nodes[0]
-	}
-	return nil
+	return Find(n, Tag(tag...))
 }
 
 //Executes depth-first search on all child nodes of n and returns the first element that matches
 //the given tag and contains all given attributes. Returns nil if no match was found.
 func FirstElementByTagAndAttr(n *html.Node, tag atom.Atom, attr ...html.Attribute) *html.Node {
-	nodes := make([]*html.Node, 0, 1)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.TagFilter(tag, cond.MatchAttrs(&nodes, true, attr...))), nil)
-	if len(nodes) > 0 {
-		return nodes[0]
-	}
-	return nil
+	return Find(n, And(Tag(tag), Attr(attr...)))
 }
 
 //Executes depth-first search on all child nodes of n and returns all elements that are a member of all given classes.
 //Returns nil if no such elements were found.
 func ElementsByClassName(n *html.Node, name ...string) []*html.Node {
-	nodes := make([]*html.Node, 0, 10)
-	nav.DFS(n, cond.MatchClassNames(&nodes, false, name...), nil)
-	if len(nodes) == 0 {
-		return nil
-	}
-	return nodes
+	return FindAll(n, Class(name...))
 }
 
 //Executes depth-first search on all child nodes of n and returns all elements that match at least one of the given tags.
 //Returns nil if no such element was found.
 func ElementsByTag(n *html.Node, tag ...atom.Atom) []*html.Node {
-	nodes := make([]*html.Node, 0, 10)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.MatchTag(&nodes, false, tag...)), nil)
-	if len(nodes) == 0 {
-		return nil
-	}
-	return nodes
+	return FindAll(n, Tag(tag...))
 }
 
 //Executes depth-first search on all child nodes of n and returns all elements that match
 //the given tag and contain all given attributes. Returns nil if no matches were found.
 func ElementsByTagAndAttr(n *html.Node, tag atom.Atom, attr ...html.Attribute) []*html.Node {
-	nodes := make([]*html.Node, 0, 10)
-	nav.DFS(n, cond.TypeFilter(html.ElementNode, cond.TagFilter(tag, cond.MatchAttrs(&nodes, false, attr...))), nil)
-	if len(nodes) == 0 {
-		return nil
-	}
-	return nodes
+	return FindAll(n, And(Tag(tag), Attr(attr...)))
 }
 
 //Returns true if node n has an attribute that matches namespace and key, returns false otherwise.
@@ -157,44 +100,94 @@ func HasAttr(n *html.Node, namespace, key string) bool {
 
 //Returns true if node n contains all given attributes, returns false otherwise.
 func MatchAttrs(n *html.Node, attr ...html.Attribute) bool {
-	nodes := make([]*html.Node, 0, 1)
-	cond.MatchAttrs(&nodes, true, attr...)(n)
-	if len(nodes) > 0 {
-		return true
-	}
-	return false
+	return cond.Attrs(attr...)(n)
 }
 
 //Returns the node's next sibling where at least one of the given tags match. Returns nil if no such node was found.
 func NextSiblingByTag(n *html.Node, tag ...atom.Atom) *html.Node {
+	return siblingMatching(n, false, Tag(tag...))
+}
+
+//Returns the node's previous sibling where at least one of the given tags match. Returns nil if no such node was found.
+func PrevSiblingByTag(n *html.Node, tag ...atom.Atom) *html.Node {
+	return siblingMatching(n, true, Tag(tag...))
+}
+
+//Returns the node's next sibling that contains all given attributes. Returns nil if no such node was found.
+func NextSiblingByAttr(n *html.Node, attr ...html.Attribute) *html.Node {
+	return siblingMatching(n, false, Attr(attr...))
+}
+
+//Returns the node's previous sibling that contains all given attributes. Returns nil if no such node was found.
+func PrevSiblingByAttr(n *html.Node, attr ...html.Attribute) *html.Node {
+	return siblingMatching(n, true, Attr(attr...))
+}
+
+//Returns the node's next sibling that is a member of all given classes. Returns nil if no such node was found.
+func NextSiblingByClassName(n *html.Node, name ...string) *html.Node {
+	return siblingMatching(n, false, Class(name...))
+}
+
+//Returns the node's previous sibling that is a member of all given classes. Returns nil if no such node was found.
+func PrevSiblingByClassName(n *html.Node, name ...string) *html.Node {
+	return siblingMatching(n, true, Class(name...))
+}
+
+func siblingMatching(n *html.Node, reverse bool, m Matcher) *html.Node {
 	nodes := make([]*html.Node, 0, 1)
-	nav.Siblings(n, false, cond.TypeFilter(html.ElementNode, cond.MatchTag(&nodes, true, tag...)), nil)
+	nav.Siblings(n, reverse, cond.Match(&nodes, true, m), nil)
 	if len(nodes) > 0 {
 		return nodes[0]
 	}
 	return nil
 }
 
+//Returns all nodes that follow n up to, but not including, the first sibling matched by m.
+//If no sibling matches, all following siblings are returned. Returns nil if there are no such nodes.
+func SiblingsUntil(n *html.Node, m Matcher) []*html.Node {
+	var nodes []*html.Node
+	nav.Siblings(n, false, func(s *html.Node) bool {
+		if m(s) {
+			return false
+		}
+		nodes = append(nodes, s)
+		return true
+	}, nil)
+	return nodes
+}
+
+//Returns all siblings of n that are elements in document order, n itself is not included.
+//Returns nil if n has no element siblings.
+func ElementSiblings(n *html.Node) []*html.Node {
+	first := n
+	for first.PrevSibling != nil {
+		first = first.PrevSibling
+	}
+	var nodes []*html.Node
+	for c := first; c != nil; c = c.NextSibling {
+		if c != n && c.Type == html.ElementNode {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
 //Returns the node's next sibling that is an element. Returns nil if no such element was found.
 func NextElementSibling(n *html.Node) *html.Node {
-	sibl := n.NextSibling
-	if sibl == nil {
-		return nil
-	}
-	if sibl.Type == html.ElementNode {
-		return sibl
+	for sibl := n.NextSibling; sibl != nil; sibl = sibl.NextSibling {
+		if sibl.Type == html.ElementNode {
+			return sibl
+		}
 	}
-	return NextElementSibling(sibl)
+	return nil
 }
 
 //Returns the node's next previous sibling that is an element. Returns nil if no such element was found.
 func PrevElementSibling(n *html.Node) *html.Node {
-	sibl := n.PrevSibling
-	if sibl == nil {
-		return nil
-	}
-	if sibl.Type == html.ElementNode {
-		return sibl
+	for sibl := n.PrevSibling; sibl != nil; sibl = sibl.PrevSibling {
+		if sibl.Type == html.ElementNode {
+			return sibl
+		}
 	}
-	return PrevElementSibling(sibl)
+	return nil
 }
@@ -69,8 +69,8 @@ func TestHasAttr(t *testing.T) {
 func TestMatchAttrs(t *testing.T) {
 
 	nodeattr := make([]html.Attribute, 0, 2)
-	nodeattr = append(nodeattr, html.Attribute{"test", "id", "1337"})
-	nodeattr = append(nodeattr, html.Attribute{"", "src", "https://example.net/image.jpg"})
+	nodeattr = append(nodeattr, html.Attribute{Namespace: "test", Key: "id", Val: "1337"})
+	nodeattr = append(nodeattr, html.Attribute{Key: "src", Val: "https://example.net/image.jpg"})
 	n := &html.Node{Data: "test", Attr: nodeattr}
 
 	musthave := make([]html.Attribute, len(nodeattr))
@@ -80,7 +80,7 @@ func TestMatchAttrs(t *testing.T) {
 		t.Errorf("%v and %v were expected to be equal", n.Attr, musthave)
 	}
 
-	musthave = append(musthave, html.Attribute{"", "alt", "test"})
+	musthave = append(musthave, html.Attribute{Key: "alt", Val: "test"})
 
 	if MatchAttrs(n, musthave...) {
 		t.Errorf("%v and %v were not expected to be equal", n.Attr, musthave)
@@ -439,3 +439,95 @@ func TestNextSiblingByTag(t *testing.T) {
 	expect(parent.FirstChild, "id", "pre1", atom.Pre, atom.A)
 	expect(parent.FirstChild, "id", "StartTestNextSiblingByTag", atom.Pre, atom.A, atom.P)
 }
+
+func TestSiblingLookup(t *testing.T) {
+	const testDoc = "siblings.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intro := ElementByID(root, "intro")
+	usage := ElementByID(root, "usage")
+	p3 := ElementByID(root, "p3")
+	en := html.Attribute{Key: "lang", Val: "en"}
+
+	expectID := func(name string, n *html.Node, id string) {
+		t.Helper()
+		if n == nil {
+			t.Errorf("%s: expected element \"%s\", got nil", name, id)
+		} else if got := AttrVal(n, "", "id"); got != id {
+			t.Errorf("%s: expected element \"%s\", got \"%s\"", name, id, got)
+		}
+	}
+
+	expectID("PrevSiblingByTag", PrevSiblingByTag(p3, atom.H2), "usage")
+	expectID("PrevSiblingByTag", PrevSiblingByTag(usage, atom.H2), "intro")
+	if PrevSiblingByTag(intro, atom.H2, atom.P) != nil {
+		t.Error("Expected return value nil")
+	}
+	expectID("NextSiblingByAttr", NextSiblingByAttr(intro, en), "p2")
+	expectID("PrevSiblingByAttr", PrevSiblingByAttr(p3, en), "p2")
+	if NextSiblingByAttr(p3, en) != nil {
+		t.Error("Expected return value nil")
+	}
+	expectID("NextSiblingByClassName", NextSiblingByClassName(intro, "text"), "p1")
+	expectID("NextSiblingByClassName", NextSiblingByClassName(intro, "text", "lead"), "p1")
+	expectID("PrevSiblingByClassName", PrevSiblingByClassName(p3, "lead"), "p1")
+}
+
+func TestSiblingsUntil(t *testing.T) {
+	const testDoc = "siblings.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intro := ElementByID(root, "intro")
+	section := SiblingsUntil(intro, Tag(atom.H2))
+	var text []string
+	for _, n := range section {
+		if n.Type == html.TextNode {
+			if s := strings.TrimSpace(n.Data); s != "" {
+				text = append(text, s)
+			}
+		}
+	}
+	if len(text) != 1 || text[0] != "text between" {
+		t.Errorf("Expected the text node between the paragraphs, got %q", text)
+	}
+	var elems []*html.Node
+	for _, n := range section {
+		if n.Type == html.ElementNode {
+			elems = append(elems, n)
+		}
+	}
+	expectIDs(t, "SiblingsUntil", elems, "p1", "p2")
+	if last := section[len(section)-1]; last.NextSibling != ElementByID(root, "usage") {
+		t.Error("SiblingsUntil must stop right before the matching sibling")
+	}
+
+	rest := SiblingsUntil(ElementByID(root, "usage"), Tag(atom.H2))
+	if len(rest) != 3 || rest[1] != ElementByID(root, "p3") {
+		t.Error("SiblingsUntil should return all following siblings if none matches")
+	}
+	if SiblingsUntil(intro, Type(html.TextNode)) != nil {
+		t.Error("Expected return value nil")
+	}
+}
+
+func TestElementSiblings(t *testing.T) {
+	const testDoc = "siblings.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectIDs(t, "ElementSiblings", ElementSiblings(ElementByID(root, "p2")), "intro", "p1", "usage", "p3")
+	if ElementSiblings(FirstElementByTag(root, atom.Html)) != nil {
+		t.Error("Expected return value nil")
+	}
+}
@@ -0,0 +1,397 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/cond"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"slices"
+	"strings"
+)
+
+//Policy is an allowlist of the elements, attributes, URL schemes and style properties that Sanitize keeps.
+//Elements and attributes are selected by Matchers, e.g.
+//	new(Policy).AllowElements(Tag(atom.P, atom.A)).AllowAttrs(Tag(atom.A), "href").AllowURLSchemes("href", "https")
+//The zero Policy allows no elements and therefore only keeps text. The configuring methods return p to allow chaining.
+//SVG and MathML elements have to be allowed with AllowForeignElements, AllowElements only applies to HTML elements.
+//A Policy must not be modified while it is used, but it can be used by several goroutines at once.
+type Policy struct {
+	elements       []Matcher
+	foreign        []Matcher
+	drop           []Matcher
+	attrs          []attrRule
+	schemes        map[string][]string
+	styles         map[string]bool
+	rel            []string
+	dropDisallowed bool
+}
+
+//attrRule allows the attributes keys on the elements matched by m, m is nil for all allowed elements.
+type attrRule struct {
+	m    Matcher
+	keys []string
+}
+
+//Disallowed elements that are removed with their content instead of being unwrapped, because their content
+//is not displayed or is not meant to be read as text.
+var sanitizeDropped = Tag(atom.Frameset, atom.Head, atom.Iframe, atom.Noembed, atom.Noframes, atom.Noscript,
+	atom.Script, atom.Style, atom.Template, atom.Title)
+
+//Allows the HTML elements matched by any of the given matchers.
+func (p *Policy) AllowElements(m ...Matcher) *Policy {
+	p.elements = append(p.elements, m...)
+	return p
+}
+
+//Allows the SVG and MathML elements matched by any of the given matchers. They are not allowed by AllowElements,
+//because Tag also matches foreign elements that share their name with an HTML element, like the a element of SVG.
+func (p *Policy) AllowForeignElements(m ...Matcher) *Policy {
+	p.foreign = append(p.foreign, m...)
+	return p
+}
+
+//Allows the attributes with the given keys on the allowed elements that are matched by m, or on all allowed elements
+//if m is nil. Namespaced attributes of foreign elements are given with their prefix, e.g. "xlink:href".
+//Keys are compared case-insensitively.
+func (p *Policy) AllowAttrs(m Matcher, key ...string) *Policy {
+	keys := make([]string, len(key))
+	for i, k := range key {
+		keys[i] = strings.ToLower(k)
+	}
+	p.attrs = append(p.attrs, attrRule{m: m, keys: keys})
+	return p
+}
+
+//Allows absolute URLs with the given schemes, e.g. "https" or "mailto", in the URL attributes with the given key.
+//URLs in srcset and style attributes are checked against the schemes allowed for "srcset" and "style".
+//Relative URLs are always allowed, URLs that cannot be parsed never.
+func (p *Policy) AllowURLSchemes(key string, scheme ...string) *Policy {
+	if p.schemes == nil {
+		p.schemes = make(map[string][]string)
+	}
+	key = strings.ToLower(key)
+	for _, s := range scheme {
+		p.schemes[key] = append(p.schemes[key], strings.ToLower(s))
+	}
+	return p
+}
+
+//Allows the given CSS properties in style attributes. The style attribute itself has to be allowed with AllowAttrs.
+//Declarations of other properties are removed, as are declarations whose value contains escapes, comments,
+//expressions or URLs that are not allowed for "style".
+func (p *Policy) AllowStyleProperties(prop ...string) *Policy {
+	if p.styles == nil {
+		p.styles = make(map[string]bool)
+	}
+	for _, s := range prop {
+		p.styles[strings.ToLower(s)] = true
+	}
+	return p
+}
+
+//Removes the disallowed elements matched by any of the given matchers with their content instead of unwrapping them.
+//Script, style and other elements whose content is not displayed as text are always removed unless they are allowed.
+func (p *Policy) DropElements(m ...Matcher) *Policy {
+	p.drop = append(p.drop, m...)
+	return p
+}
+
+//Removes all disallowed elements with their content if drop is true. By default disallowed HTML elements are unwrapped,
+//that is replaced by their sanitized children. Disallowed SVG and MathML elements are always removed with their content.
+func (p *Policy) DropDisallowed(drop bool) *Policy {
+	p.dropDisallowed = drop
+	return p
+}
+
+//Adds the given tokens like "noopener" or "nofollow" to the rel attribute of every a and area element with an href attribute.
+//The rel attribute is created if necessary, other tokens are kept if the rel attribute is allowed.
+func (p *Policy) RequireRel(token ...string) *Policy {
+	p.rel = append(p.rel, token...)
+	return p
+}
+
+//Removes everything from the descendants of n that p does not allow: disallowed elements are unwrapped or removed,
+//disallowed SVG and MathML elements are always removed,
+//disallowed attributes and URLs are removed and style attributes are reduced to the allowed declarations.
+//Comments, doctypes and other nodes that are neither elements nor text are removed as well.
+//n itself is not changed, so it can be a document or a container for the nodes returned by ParseFragment.
+func (p *Policy) Sanitize(n *html.Node) {
+	//the tree is traversed without recursion, disallowed elements are unwrapped after their children were sanitized
+	unwrap := make(map[*html.Node]bool)
+	c := n.FirstChild
+	for c != nil {
+		remove := false
+		switch {
+		case c.Type == html.TextNode:
+		case c.Type != html.ElementNode:
+			remove = true
+		case p.allowed(c):
+			p.sanitizeAttrs(c)
+		case p.dropDisallowed || !isHTMLElement(c) || sanitizeDropped(c) || slices.ContainsFunc(p.drop, func(m Matcher) bool { return m(c) }):
+			remove = true
+		default:
+			unwrap[c] = true
+		}
+		if !remove && c.FirstChild != nil {
+			c = c.FirstChild
+			continue
+		}
+		//leave c and every ancestor whose last child c is
+		for c != n {
+			next, parent := c.NextSibling, c.Parent
+			if remove {
+				parent.RemoveChild(c)
+				remove = false
+			} else if unwrap[c] {
+				delete(unwrap, c)
+				Unwrap(c)
+			}
+			if next != nil {
+				c = next
+				break
+			}
+			c = parent
+		}
+		if c == n {
+			return
+		}
+	}
+}
+
+//Parses s as the content of a body element, sanitizes it and returns the rendered result.
+func (p *Policy) SanitizeString(s string) (string, error) {
+	nodes, err := ParseFragment(nil, s)
+	if err != nil {
+		return "", err
+	}
+	container := &html.Node{Type: html.DocumentNode}
+	for _, c := range nodes {
+		container.AppendChild(c)
+	}
+	p.Sanitize(container)
+	var b strings.Builder
+	for c := container.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&b, c); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func (p *Policy) allowed(n *html.Node) bool {
+	elements := p.elements
+	if !isHTMLElement(n) {
+		elements = p.foreign
+	}
+	return slices.ContainsFunc(elements, func(m Matcher) bool { return m(n) })
+}
+
+//Removes the attributes of the allowed element n that p does not allow and enforces the rel tokens.
+func (p *Policy) sanitizeAttrs(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		key := strings.ToLower(a.Key)
+		if a.Namespace != "" {
+			key = a.Namespace + ":" + key
+		}
+		if !p.attrAllowed(n, key) {
+			continue
+		}
+		if val, ok := p.attrVal(n, key, a.Val); ok {
+			a.Val = val
+			attrs = append(attrs, a)
+		}
+	}
+	n.Attr = attrs
+	if len(p.rel) > 0 && (n.DataAtom == atom.A || n.DataAtom == atom.Area) && (HasAttr(n, "", "href") || HasAttr(n, "xlink", "href")) {
+		rel := cond.Tokens(AttrVal(n, "", "rel"))
+		for _, t := range p.rel {
+			if !slices.ContainsFunc(rel, func(s string) bool { return strings.EqualFold(s, t) }) {
+				rel = append(rel, t)
+			}
+		}
+		SetAttr(n, "", "rel", strings.Join(rel, " "))
+	}
+}
+
+func (p *Policy) attrAllowed(n *html.Node, key string) bool {
+	for _, r := range p.attrs {
+		if (r.m == nil || r.m(n)) && slices.Contains(r.keys, key) {
+			return true
+		}
+	}
+	return false
+}
+
+//Returns the sanitized value of the attribute key of n, ok is false if the attribute has to be removed.
+func (p *Policy) attrVal(n *html.Node, key, val string) (string, bool) {
+	switch {
+	case key == "style":
+		val = p.sanitizeStyle(val)
+		return val, val != ""
+	case key == "ping" && (n.DataAtom == atom.A || n.DataAtom == atom.Area):
+		var urls []string
+		for _, u := range strings.Fields(val) {
+			if p.urlAllowed(key, u) {
+				urls = append(urls, u)
+			}
+		}
+		return strings.Join(urls, " "), urls != nil
+	case key == "srcset" && (n.DataAtom == atom.Img || n.DataAtom == atom.Source):
+		var candidates []srcsetCandidate
+		for _, c := range parseSrcset(val) {
+			if p.urlAllowed(key, c.URL) {
+				candidates = append(candidates, c)
+			}
+		}
+		return formatSrcset(candidates), candidates != nil
+	case key == "content" && n.DataAtom == atom.Meta:
+		if v, ok := attrValFold(n, "http-equiv"); ok && strings.EqualFold(strings.Trim(v, asciiWhitespace), "refresh") {
+			if start, end, ok := refreshURL(val); ok {
+				return val, p.urlAllowed(key, val[start:end])
+			}
+		}
+	case slices.Contains(urlAttrs[n.DataAtom], key) || foreignURLAttrs[key] && !isHTMLElement(n):
+		return val, p.urlAllowed(key, val)
+	}
+	return val, true
+}
+
+//URL attributes of SVG and MathML elements.
+var foreignURLAttrs = map[string]bool{"href": true, "src": true, "xlink:href": true, "definitionurl": true}
+
+//Reports whether the URL v is relative or its scheme is allowed for the attribute key.
+func (p *Policy) urlAllowed(key, v string) bool {
+	u, err := parseURLAttr(v)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "" || slices.Contains(p.schemes[key], strings.ToLower(u.Scheme))
+}
+
+//Returns the declarations of the style attribute css whose properties and values are allowed, separated by "; ".
+func (p *Policy) sanitizeStyle(css string) string {
+	var decls []string
+	for _, d := range splitDeclarations(css) {
+		prop, val, ok := strings.Cut(d, ":")
+		prop = strings.ToLower(strings.Trim(prop, asciiWhitespace))
+		val = strings.Trim(val, asciiWhitespace)
+		if ok && val != "" && p.styles[prop] && p.styleValueAllowed(val) {
+			decls = append(decls, prop+": "+val)
+		}
+	}
+	return strings.Join(decls, "; ")
+}
+
+func (p *Policy) styleValueAllowed(val string) bool {
+	lower := strings.ToLower(val)
+	for _, s := range []string{`\`, "/*", "<", "expression(", "image-set(", "@import"} {
+		if strings.Contains(lower, s) {
+			return false
+		}
+	}
+	urls := cssURL.FindAllStringSubmatch(val, -1)
+	if len(urls) != strings.Count(lower, "url(") {
+		return false
+	}
+	for _, m := range urls {
+		if !p.urlAllowed("style", m[1]+m[2]+m[3]) {
+			return false
+		}
+	}
+	return true
+}
+
+//Splits the value of a style attribute at the semicolons that are not part of a string or of parentheses.
+func splitDeclarations(css string) []string {
+	var decls []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(css); i++ {
+		switch c := css[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth > 0:
+			depth--
+		case c == ';' && depth == 0:
+			decls = append(decls, css[start:i])
+			start = i + 1
+		}
+	}
+	return append(decls, css[start:])
+}
+
+//Returns a policy that allows no elements, the result of Sanitize is plain text.
+func StrictTextPolicy() *Policy {
+	return new(Policy)
+}
+
+//Returns a policy for user generated content like comments or forum posts. It allows text formatting, headings, lists,
+//quotes, code, tables and images, but no style or class attributes. Links may use the http, https and mailto schemes,
+//images and quotes http and https. Links get rel="nofollow noopener ugc".
+func UGCPolicy() *Policy {
+	p := new(Policy).AllowElements(Tag(
+		atom.A, atom.Abbr, atom.B, atom.Bdi, atom.Bdo, atom.Blockquote, atom.Br, atom.Caption, atom.Cite, atom.Code,
+		atom.Col, atom.Colgroup, atom.Dd, atom.Del, atom.Details, atom.Dfn, atom.Div, atom.Dl, atom.Dt, atom.Em,
+		atom.Figcaption, atom.Figure, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Hr, atom.I, atom.Img,
+		atom.Ins, atom.Kbd, atom.Li, atom.Mark, atom.Ol, atom.P, atom.Picture, atom.Pre, atom.Q, atom.Rp, atom.Rt,
+		atom.Ruby, atom.S, atom.Samp, atom.Small, atom.Source, atom.Span, atom.Strong, atom.Sub, atom.Summary, atom.Sup,
+		atom.Table, atom.Tbody, atom.Td, atom.Tfoot, atom.Th, atom.Thead, atom.Time, atom.Tr, atom.U, atom.Ul, atom.Var,
+		atom.Wbr,
+	))
+	p.AllowAttrs(nil, "dir", "lang", "title")
+	p.AllowAttrs(Tag(atom.A), "href", "hreflang", "rel")
+	p.AllowAttrs(Tag(atom.Img), "alt", "height", "src", "srcset", "sizes", "width")
+	p.AllowAttrs(Tag(atom.Source), "media", "sizes", "srcset", "type")
+	p.AllowAttrs(Tag(atom.Blockquote, atom.Del, atom.Ins, atom.Q), "cite")
+	p.AllowAttrs(Tag(atom.Del, atom.Ins, atom.Time), "datetime")
+	p.AllowAttrs(Tag(atom.Ol), "reversed", "start", "type")
+	p.AllowAttrs(Tag(atom.Li), "value")
+	p.AllowAttrs(Tag(atom.Col, atom.Colgroup), "span")
+	p.AllowAttrs(Tag(atom.Td, atom.Th), "colspan", "headers", "rowspan")
+	p.AllowAttrs(Tag(atom.Th), "abbr", "scope")
+	p.AllowAttrs(Tag(atom.Details), "open")
+	p.AllowURLSchemes("href", "http", "https", "mailto")
+	p.AllowURLSchemes("src", "http", "https")
+	p.AllowURLSchemes("srcset", "http", "https")
+	p.AllowURLSchemes("cite", "http", "https")
+	return p.RequireRel("nofollow", "noopener", "ugc")
+}
+
+//Returns a policy for HTML e-mails, which are laid out with tables, presentational attributes and inline styles.
+//Style attributes may contain common text, box and table properties, but no backgrounds. Images may use the http, https and cid
+//schemes, links http, https, mailto and tel. Links get rel="noopener".
+func EmailPolicy() *Policy {
+	p := UGCPolicy()
+	p.rel = nil
+	p.AllowElements(Tag(atom.Center, atom.Font, atom.Tt))
+	p.AllowAttrs(nil, "style")
+	p.AllowAttrs(Tag(atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.P, atom.Table, atom.Tbody,
+		atom.Td, atom.Tfoot, atom.Th, atom.Thead, atom.Tr, atom.Col, atom.Colgroup, atom.Caption, atom.Img, atom.Hr), "align")
+	p.AllowAttrs(Tag(atom.Table, atom.Tbody, atom.Td, atom.Tfoot, atom.Th, atom.Thead, atom.Tr, atom.Col, atom.Colgroup),
+		"valign")
+	p.AllowAttrs(Tag(atom.Table, atom.Td, atom.Th, atom.Tr), "bgcolor")
+	p.AllowAttrs(Tag(atom.Table), "border", "cellpadding", "cellspacing", "width")
+	p.AllowAttrs(Tag(atom.Td, atom.Th, atom.Col, atom.Colgroup, atom.Hr), "height", "nowrap", "width")
+	p.AllowAttrs(Tag(atom.Img), "border", "hspace", "vspace")
+	p.AllowAttrs(Tag(atom.Font), "color", "face", "size")
+	p.AllowAttrs(Tag(atom.A), "target")
+	p.AllowURLSchemes("href", "tel")
+	p.AllowURLSchemes("src", "cid")
+	p.AllowStyleProperties(
+		"background-color", "border", "border-bottom", "border-collapse", "border-color", "border-left", "border-radius",
+		"border-right", "border-spacing", "border-style", "border-top", "border-width", "color", "display", "font",
+		"font-family", "font-size", "font-style", "font-weight", "height", "letter-spacing", "line-height", "margin",
+		"margin-bottom", "margin-left", "margin-right", "margin-top", "max-width", "min-width", "padding",
+		"padding-bottom", "padding-left", "padding-right", "padding-top", "text-align", "text-decoration",
+		"text-transform", "vertical-align", "white-space", "width",
+	)
+	return p.RequireRel("noopener")
+}
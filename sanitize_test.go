@@ -0,0 +1,116 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	const testDoc = "sanitize.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := FirstElementByTag(root, atom.Body)
+	UGCPolicy().Sanitize(body)
+	if n := Find(body, Or(Type(html.CommentNode), Tag(atom.Script, atom.Font, atom.Form, atom.Input))); n != nil {
+		t.Errorf("Unexpected node %v", n)
+	}
+	expect := `<body><h1 title="kept">Post</h1><p>Hello <b>world</b>, <a href="https://example.org/" rel="nofollow noopener ugc">link</a>` +
+		` <a rel="nofollow noopener ugc" href="/relative">relative</a> <a>script</a> text ` +
+		`<img srcset="a.png 1x, https://example.org/b.png 2x" alt="img"/></p><ul><li>name</li></ul>` + "\n\n</body>"
+	if s := renderString(t, body); s != expect {
+		t.Errorf("Expected %s, got %s", expect, s)
+	}
+
+	//a document keeps nothing but text
+	StrictTextPolicy().Sanitize(root)
+	if root.FirstChild == nil || root.FirstChild.Type != html.TextNode || len(FindAll(root, Not(Type(html.TextNode)))) != 1 {
+		t.Error("Only text must be left")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	custom := new(Policy).
+		AllowElements(Tag(atom.P, atom.A, atom.Span), And(Tag(atom.Div), Class("box"))).
+		AllowAttrs(nil, "class").
+		AllowAttrs(Tag(atom.A), "href", "ping").
+		AllowAttrs(Tag(atom.Span), "style").
+		AllowURLSchemes("href", "https").
+		AllowURLSchemes("ping", "https").
+		AllowURLSchemes("style", "https").
+		AllowStyleProperties("color", "background-image").
+		DropElements(Tag(atom.Aside))
+	isMathML := func(n *html.Node) bool { return n.Namespace == "math" }
+	mathml := UGCPolicy().AllowForeignElements(And(isMathML, Tag(atom.Math, atom.Mi, atom.Mtext, atom.A))).AllowAttrs(isMathML, "href")
+	svg := UGCPolicy().AllowForeignElements(func(n *html.Node) bool {
+		return n.Namespace == "svg" && (n.Data == "svg" || n.Data == "a" || n.Data == "text")
+	}).AllowAttrs(Tag(atom.A), "xlink:href")
+	tests := []struct {
+		policy *Policy
+		src    string
+		expect string
+	}{
+		{StrictTextPolicy(), `<p>a <b>b</b></p><script>alert(1)</script><!-- c --><p>d &lt;e&gt;</p>`, "a bd &lt;e&gt;"},
+		{UGCPolicy(), `<a href="javascript:alert(1)" onclick="x()">a</a>`, `<a>a</a>`},
+		{UGCPolicy(), `<a href=" java&#09;script:alert(1)">a</a>`, `<a>a</a>`},
+		{UGCPolicy(), `<a href="HTTPS://example.org/" rel="author" target="_blank">a</a>`, `<a href="HTTPS://example.org/" rel="author nofollow noopener ugc">a</a>`},
+		{UGCPolicy(), `<img src="data:image/png;base64,AAA=" alt="x"><img srcset="javascript:x 1x, a.png 2x">`, `<img alt="x"/><img srcset="a.png 2x"/>`},
+		{UGCPolicy(), `<p style="color: red" class="x">a</p><svg><a href="javascript:x"><text>b</text></a></svg>`, `<p>a</p>`},
+		{UGCPolicy(), `<math><a href="javascript:alert(1)">x</a></math><math><img src="javascript:x"></math>c`, `c`},
+		{UGCPolicy(), `<math><mtext><b>a</b></mtext><mi href="javascript:x">b</mi></math>`, ``},
+		{mathml, `<math><mi href="javascript:x">a</mi><mi href="https://example.org/">b</mi><mtext><b>c</b></mtext></math>`,
+			`<math><mi>a</mi><mi href="https://example.org/">b</mi><mtext><b>c</b></mtext></math>`},
+		{mathml, `<math><a href="javascript:x">a</a><a href="/b">b</a></math>`, `<math><a>a</a><a href="/b" rel="nofollow noopener ugc">b</a></math>`},
+		{svg, `<svg><a xlink:href="javascript:x"><text>a</text></a><a href="https://example.org/"><text>b</text></a><foreignObject><b>c</b></foreignObject></svg>`,
+			`<svg><a><text>a</text></a><a href="https://example.org/" rel="nofollow noopener ugc"><text>b</text></a></svg>`},
+		{UGCPolicy(), `<iframe src="https://example.org/"></iframe><noscript>a</noscript><form><button>b</button></form>`, `b`},
+		{EmailPolicy(), `<table bgcolor="#fff" onload="x()"><tr><td style="color: red; background: url(https://t.example/p.gif); position: fixed">a</td></tr></table>`,
+			`<table bgcolor="#fff"><tbody><tr><td style="color: red">a</td></tr></tbody></table>`},
+		{EmailPolicy(), `<a href="tel:+123" target="_blank">a</a><img src="cid:logo">`, `<a href="tel:+123" target="_blank" rel="noopener">a</a><img src="cid:logo"/>`},
+		{custom, `<div class="box"><div>a</div></div>`, `<div class="box">a</div>`},
+		{custom, `<aside>a</aside><section>b</section>`, `b`},
+		{custom, `<a href="http://example.org/" ping="https://p.example/ /p javascript:x">a</a>`, `<a ping="https://p.example/ /p">a</a>`},
+		{custom, `<span style="color:blue;font-size:9px; background-image:url( 'https://e.example/a;b.png' )">a</span>`,
+			`<span style="color: blue; background-image: url( &#39;https://e.example/a;b.png&#39; )">a</span>`},
+		{custom, `<span style="background-image: url(http://e.example/a.png)">a</span>`, `<span>a</span>`},
+		{custom, `<span style="color: expression(alert(1)); COLOR: r\65 d">a</span>`, `<span>a</span>`},
+		{new(Policy).AllowElements(Tag(atom.P)).DropDisallowed(true), `<p>a<b>b</b></p><div>c</div>`, `<p>a</p>`},
+	}
+	for _, test := range tests {
+		s, err := test.policy.SanitizeString(test.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.expect {
+			t.Errorf("%s: expected %s, got %s", test.src, test.expect, s)
+		}
+	}
+}
+
+func TestSplitDeclarations(t *testing.T) {
+	decls := splitDeclarations(`a: 1; b: url("x;y"); c: f(1;2);`)
+	if len(decls) != 4 || decls[1] != ` b: url("x;y")` || decls[2] != " c: f(1;2)" {
+		t.Errorf("Unexpected declarations %q", decls)
+	}
+}
+
+func TestSanitizeDeep(t *testing.T) {
+	root := &html.Node{Type: html.DocumentNode}
+	n := root
+	for i := 0; i < 1000; i++ {
+		c := &html.Node{Type: html.ElementNode, Data: "span", DataAtom: atom.Span}
+		c.AppendChild(&html.Node{Type: html.TextNode, Data: "x"})
+		n.AppendChild(c)
+		n = c
+	}
+	n.AppendChild(&html.Node{Type: html.ElementNode, Data: "b", DataAtom: atom.B})
+	new(Policy).AllowElements(Tag(atom.B)).Sanitize(root)
+	if len(FindAll(root, Type(html.TextNode))) != 1000 || Find(root, Tag(atom.Span)) != nil || root.LastChild.DataAtom != atom.B {
+		t.Error("Nested elements were not unwrapped")
+	}
+}
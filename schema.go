@@ -0,0 +1,762 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//DataSource names the syntax a value of a schema.org entity was taken from.
+type DataSource string
+
+const (
+	SourceJSONLD    DataSource = "json-ld"
+	SourceMicrodata DataSource = "microdata"
+	SourceRDFa      DataSource = "rdfa"
+	SourceOpenGraph DataSource = "opengraph"
+	SourceMeta      DataSource = "meta" //title, meta and link elements that are not part of OpenGraph
+)
+
+//SchemaEntities holds the schema.org entities of a document as returned by SchemaOrg.
+type SchemaEntities struct {
+	Products    []*Product
+	Articles    []*Article
+	Recipes     []*Recipe
+	Events      []*Event
+	Breadcrumbs []*BreadcrumbList
+}
+
+//SchemaEntity holds the fields common to all schema.org entities.
+type SchemaEntity struct {
+	Type    string                //the schema.org type, e.g. "NewsArticle" for an Article
+	ID      string                //the @id, itemid or RDFa subject IRI
+	Sources map[string]DataSource //the source of each non-empty field by field name
+}
+
+//Product is a schema.org Product.
+type Product struct {
+	SchemaEntity
+	Name        string
+	Description string
+	Image       string
+	URL         string
+	SKU         string
+	GTIN        string
+	Brand       string
+	Offers      []Offer
+	Rating      string //the ratingValue of the aggregateRating
+	RatingCount string //the ratingCount or reviewCount of the aggregateRating
+}
+
+//Offer is a schema.org Offer or AggregateOffer.
+type Offer struct {
+	Price         string //the price, or the lowPrice of an AggregateOffer
+	PriceCurrency string
+	Availability  string //the local name of the availability, e.g. "InStock"
+	URL           string
+}
+
+//Article is a schema.org Article or one of its subtypes like NewsArticle or BlogPosting.
+type Article struct {
+	SchemaEntity
+	Headline      string
+	Description   string
+	Image         string
+	URL           string
+	Authors       []string
+	Publisher     string
+	DatePublished string
+	DateModified  string
+}
+
+//Recipe is a schema.org Recipe.
+type Recipe struct {
+	SchemaEntity
+	Name         string
+	Description  string
+	Image        string
+	URL          string
+	Authors      []string
+	PrepTime     string
+	CookTime     string
+	TotalTime    string
+	Yield        string
+	Category     string
+	Cuisine      string
+	Ingredients  []string
+	Instructions []string //the text of each step, steps of sections are listed in order
+}
+
+//Event is a schema.org Event or one of its subtypes like MusicEvent.
+type Event struct {
+	SchemaEntity
+	Name        string
+	Description string
+	Image       string
+	URL         string
+	StartDate   string
+	EndDate     string
+	Status      string //the local name of the eventStatus, e.g. "EventScheduled"
+	Location    string //the name of the location
+	Address     string //the address of the location, the parts of a PostalAddress are joined by ", "
+	Organizer   string
+	Offers      []Offer
+}
+
+//BreadcrumbList is a schema.org BreadcrumbList.
+type BreadcrumbList struct {
+	SchemaEntity
+	Items []BreadcrumbItem //sorted by position
+}
+
+//BreadcrumbItem is a ListItem of a BreadcrumbList.
+type BreadcrumbItem struct {
+	Position int
+	Name     string
+	URL      string
+}
+
+var articleTypes = map[string]bool{
+	"AdvertiserContentArticle": true,
+	"AnalysisNewsArticle":      true,
+	"Article":                  true,
+	"BackgroundNewsArticle":    true,
+	"BlogPosting":              true,
+	"LiveBlogPosting":          true,
+	"NewsArticle":              true,
+	"OpinionNewsArticle":       true,
+	"Report":                   true,
+	"ReportageNewsArticle":     true,
+	"ReviewNewsArticle":        true,
+	"SatiricalArticle":         true,
+	"ScholarlyArticle":         true,
+	"SocialMediaPosting":       true,
+	"TechArticle":              true,
+}
+
+var productTypes = map[string]bool{
+	"Car":               true,
+	"IndividualProduct": true,
+	"Product":           true,
+	"ProductGroup":      true,
+	"ProductModel":      true,
+	"SomeProducts":      true,
+	"Vehicle":           true,
+}
+
+//Returns the schema.org products, articles, recipes, events and breadcrumb lists of the document that contains doc.
+//Entities are collected from JSON-LD, microdata and RDFa, including entities nested in other entities, and converted to typed structs.
+//Types and properties are matched by their local name, so both http and https schema.org IRIs as well as schema: CURIEs work.
+//Entities that appear in several syntaxes are merged if they have the same ID or, lacking IDs, the same type and name,
+//values are taken from JSON-LD first, then microdata, then RDFa. If a document has exactly one article or product, fields that
+//are missing are filled from OpenGraph and meta elements. If it has none, but its og:type is article or product, an entity
+//is built from OpenGraph and meta elements alone. The Sources of each entity record where each field came from.
+func SchemaOrg(doc *html.Node, pageURL *url.URL) *SchemaEntities {
+	doc = treeRoot(doc)
+	var nodes []*schemaNode
+	for _, item := range JSONLD(doc) {
+		jsonldNode(item, &nodes)
+	}
+	for _, item := range Microdata(doc, pageURL) {
+		microdataNode(item, &nodes)
+	}
+	for _, obj := range RDFaObjects(RDFa(doc, pageURL)) {
+		rdfaNode(obj, &nodes)
+	}
+	nodes = mergeSchemaNodes(nodes)
+
+	e := &SchemaEntities{}
+	for _, n := range nodes {
+		for _, t := range n.types {
+			if e.add(n, t) {
+				break
+			}
+		}
+	}
+	e.fallback(Metadata(doc))
+	return e
+}
+
+//Adds the node n as entity of type typ, returns false if typ is not supported.
+func (e *SchemaEntities) add(n *schemaNode, typ string) bool {
+	switch {
+	case productTypes[typ]:
+		e.Products = append(e.Products, newProduct(n, typ))
+	case articleTypes[typ]:
+		e.Articles = append(e.Articles, newArticle(n, typ))
+	case typ == "Recipe":
+		e.Recipes = append(e.Recipes, newRecipe(n, typ))
+	case strings.HasSuffix(typ, "Event"):
+		e.Events = append(e.Events, newEvent(n, typ))
+	case typ == "BreadcrumbList":
+		e.Breadcrumbs = append(e.Breadcrumbs, newBreadcrumbList(n, typ))
+	default:
+		return false
+	}
+	return true
+}
+
+//schemaNode is an entity or another object of structured data in a form that is independent of the syntax.
+type schemaNode struct {
+	types   []string //the local names of the types
+	id      string
+	props   map[string][]any //values by local property name, values are strings or *schemaNode
+	sources map[string]DataSource
+}
+
+func newSchemaNode() *schemaNode {
+	return &schemaNode{
+		props:   make(map[string][]any),
+		sources: make(map[string]DataSource),
+	}
+}
+
+func (n *schemaNode) add(prop string, v any, src DataSource) {
+	n.props[prop] = append(n.props[prop], v)
+	if _, ok := n.sources[prop]; !ok {
+		n.sources[prop] = src
+	}
+}
+
+//Returns the text of the first value of the first of the given properties that has a non-empty one, and that property.
+func (n *schemaNode) str(props ...string) (string, string) {
+	return n.text(map[*schemaNode]bool{n: true}, props...)
+}
+
+//Like str, seen holds the objects whose text is being determined. They have no text when referenced again,
+//which breaks reference cycles.
+func (n *schemaNode) text(seen map[*schemaNode]bool, props ...string) (string, string) {
+	for _, p := range props {
+		for _, v := range n.props[p] {
+			if s := valueText(v, seen); s != "" {
+				return s, p
+			}
+		}
+	}
+	return "", ""
+}
+
+//Returns the nested objects of the property prop.
+func (n *schemaNode) nodes(prop string) []*schemaNode {
+	var nodes []*schemaNode
+	for _, v := range n.props[prop] {
+		if c, ok := v.(*schemaNode); ok {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+//Returns the text of a property value. Objects are represented by their name, url or ID.
+func schemaText(v any) string {
+	return valueText(v, make(map[*schemaNode]bool))
+}
+
+func valueText(v any, seen map[*schemaNode]bool) string {
+	switch v := v.(type) {
+	case string:
+		return strings.Trim(v, asciiWhitespace)
+	case *schemaNode:
+		if seen[v] {
+			return ""
+		}
+		seen[v] = true
+		defer delete(seen, v)
+		if s, _ := v.text(seen, "name", "url", "contentUrl", "text"); s != "" {
+			return s
+		}
+		return v.id
+	}
+	return ""
+}
+
+//Returns the part of a type or property IRI after the last slash, hash or colon.
+func localName(iri string) string {
+	if i := strings.LastIndexAny(iri, "/#:"); i >= 0 {
+		return iri[i+1:]
+	}
+	return iri
+}
+
+func jsonldNode(m map[string]any, nodes *[]*schemaNode) *schemaNode {
+	n := newSchemaNode()
+	*nodes = append(*nodes, n)
+	for _, t := range jsonldTypes(m) {
+		n.types = append(n.types, localName(t))
+	}
+	n.id, _ = m["@id"].(string)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if !strings.HasPrefix(k, "@") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range jsonldValues(m[k], nodes) {
+			n.add(localName(k), v, SourceJSONLD)
+		}
+	}
+	return n
+}
+
+func jsonldValues(v any, nodes *[]*schemaNode) []any {
+	switch v := v.(type) {
+	case string:
+		return []any{v}
+	case float64:
+		return []any{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []any{strconv.FormatBool(v)}
+	case []any:
+		var values []any
+		for _, e := range v {
+			values = append(values, jsonldValues(e, nodes)...)
+		}
+		return values
+	case map[string]any:
+		if val, ok := v["@value"]; ok {
+			return jsonldValues(val, nodes)
+		}
+		if list, ok := v["@list"]; ok {
+			return jsonldValues(list, nodes)
+		}
+		if set, ok := v["@set"]; ok {
+			return jsonldValues(set, nodes)
+		}
+		return []any{jsonldNode(v, nodes)}
+	}
+	return nil
+}
+
+func microdataNode(item *MicrodataItem, nodes *[]*schemaNode) *schemaNode {
+	n := newSchemaNode()
+	*nodes = append(*nodes, n)
+	for _, t := range item.Type {
+		n.types = append(n.types, localName(t))
+	}
+	n.id = item.ID
+	keys := make([]string, 0, len(item.Properties))
+	for k := range item.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range item.Properties[k] {
+			if c, ok := v.(*MicrodataItem); ok {
+				n.add(localName(k), microdataNode(c, nodes), SourceMicrodata)
+			} else {
+				n.add(localName(k), v, SourceMicrodata)
+			}
+		}
+	}
+	return n
+}
+
+func rdfaNode(obj map[string]any, nodes *[]*schemaNode) *schemaNode {
+	n := newSchemaNode()
+	n.id, _ = obj["@id"].(string)
+	if len(obj) == 1 && n.id != "" {
+		//a reference, not an object
+		return n
+	}
+	*nodes = append(*nodes, n)
+	types, _ := obj["@type"].([]any)
+	for _, t := range types {
+		n.types = append(n.types, localName(t.(string)))
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		if !strings.HasPrefix(k, "@") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		values, ok := obj[k].([]any)
+		if !ok {
+			values = []any{obj[k]}
+		}
+		for _, v := range values {
+			if m, ok := v.(map[string]any); ok {
+				n.add(localName(k), rdfaNode(m, nodes), SourceRDFa)
+			} else {
+				n.add(localName(k), v, SourceRDFa)
+			}
+		}
+	}
+	return n
+}
+
+//Merges nodes that describe the same entity and replaces references to IDs and to merged nodes by the remaining nodes.
+//Returns the remaining nodes in their original order.
+func mergeSchemaNodes(nodes []*schemaNode) []*schemaNode {
+	byKey := make(map[string]*schemaNode)
+	byID := make(map[string]*schemaNode)
+	replaced := make(map[*schemaNode]*schemaNode)
+	var merged []*schemaNode
+	for _, n := range nodes {
+		if len(n.types) == 0 && len(n.props) == 0 {
+			continue
+		}
+		var keys []string
+		if n.id != "" {
+			keys = append(keys, "id "+n.id)
+		}
+		if name, _ := n.str("name", "headline"); name != "" && len(n.types) > 0 && n.id == "" {
+			keys = append(keys, "name "+n.types[0]+" "+strings.ToLower(name))
+		}
+		var first *schemaNode
+		for _, k := range keys {
+			if first = byKey[k]; first != nil {
+				break
+			}
+		}
+		if first != nil {
+			first.merge(n)
+			replaced[n] = first
+			n = first
+		} else {
+			merged = append(merged, n)
+		}
+		for _, k := range keys {
+			if byKey[k] == nil {
+				byKey[k] = n
+			}
+		}
+		if n.id != "" && byID[n.id] == nil {
+			byID[n.id] = n
+		}
+	}
+	for _, n := range merged {
+		for _, values := range n.props {
+			for i, v := range values {
+				c, ok := v.(*schemaNode)
+				switch {
+				case !ok:
+				case replaced[c] != nil:
+					values[i] = replaced[c]
+				case len(c.types) == 0 && len(c.props) == 0 && byID[c.id] != nil:
+					values[i] = byID[c.id]
+				}
+			}
+		}
+	}
+	return merged
+}
+
+//Adds the types and properties of other that n lacks.
+func (n *schemaNode) merge(other *schemaNode) {
+	for _, t := range other.types {
+		found := false
+		for _, nt := range n.types {
+			found = found || nt == t
+		}
+		if !found {
+			n.types = append(n.types, t)
+		}
+	}
+	for p, values := range other.props {
+		if _, ok := n.props[p]; !ok {
+			n.props[p] = values
+			n.sources[p] = other.sources[p]
+		}
+	}
+}
+
+//entityBuilder fills the fields of a typed entity from a schemaNode and records their sources.
+type entityBuilder struct {
+	n *schemaNode
+	e *SchemaEntity
+}
+
+//Sets dst to val if dst is empty and records src as source of field.
+func (e *SchemaEntity) fill(field string, dst *string, val string, src DataSource) {
+	if *dst == "" && val != "" {
+		*dst = val
+		e.Sources[field] = src
+	}
+}
+
+func newEntityBuilder(n *schemaNode, typ string, e *SchemaEntity) *entityBuilder {
+	*e = SchemaEntity{Type: typ, ID: n.id, Sources: make(map[string]DataSource)}
+	return &entityBuilder{n: n, e: e}
+}
+
+//Sets dst to the first value of the first of the given properties that has one.
+func (b *entityBuilder) str(field string, dst *string, props ...string) {
+	if s, p := b.n.str(props...); s != "" {
+		*dst = s
+		b.e.Sources[field] = b.n.sources[p]
+	}
+}
+
+//Sets dst to the values of the first of the given properties that has some.
+func (b *entityBuilder) strs(field string, dst *[]string, props ...string) {
+	for _, p := range props {
+		var values []string
+		for _, v := range b.n.props[p] {
+			if s := schemaText(v); s != "" {
+				values = append(values, s)
+			}
+		}
+		if values != nil {
+			*dst = values
+			b.e.Sources[field] = b.n.sources[p]
+			return
+		}
+	}
+}
+
+//Sets dst to the local name of the first value of prop, e.g. "InStock" for "https://schema.org/InStock".
+func (b *entityBuilder) enum(field string, dst *string, prop string) {
+	b.str(field, dst, prop)
+	*dst = localName(*dst)
+}
+
+func (b *entityBuilder) offers(field string, dst *[]Offer) {
+	for _, o := range b.n.nodes("offers") {
+		var offer Offer
+		offer.Price, _ = o.str("price", "lowPrice")
+		offer.PriceCurrency, _ = o.str("priceCurrency")
+		offer.URL, _ = o.str("url")
+		avail, _ := o.str("availability")
+		offer.Availability = localName(avail)
+		*dst = append(*dst, offer)
+		b.e.Sources[field] = b.n.sources["offers"]
+	}
+}
+
+func newProduct(n *schemaNode, typ string) *Product {
+	p := &Product{}
+	b := newEntityBuilder(n, typ, &p.SchemaEntity)
+	b.str("Name", &p.Name, "name")
+	b.str("Description", &p.Description, "description")
+	b.str("Image", &p.Image, "image")
+	b.str("URL", &p.URL, "url")
+	b.str("SKU", &p.SKU, "sku")
+	b.str("GTIN", &p.GTIN, "gtin", "gtin13", "gtin12", "gtin14", "gtin8")
+	b.str("Brand", &p.Brand, "brand", "manufacturer")
+	b.offers("Offers", &p.Offers)
+	for _, r := range n.nodes("aggregateRating") {
+		p.Rating, _ = r.str("ratingValue")
+		p.RatingCount, _ = r.str("ratingCount", "reviewCount")
+		b.e.Sources["Rating"] = n.sources["aggregateRating"]
+		b.e.Sources["RatingCount"] = n.sources["aggregateRating"]
+		break
+	}
+	return p
+}
+
+func newArticle(n *schemaNode, typ string) *Article {
+	a := &Article{}
+	b := newEntityBuilder(n, typ, &a.SchemaEntity)
+	b.str("Headline", &a.Headline, "headline", "name")
+	b.str("Description", &a.Description, "description")
+	b.str("Image", &a.Image, "image", "thumbnailUrl")
+	b.str("URL", &a.URL, "url", "mainEntityOfPage")
+	b.strs("Authors", &a.Authors, "author", "creator")
+	b.str("Publisher", &a.Publisher, "publisher")
+	b.str("DatePublished", &a.DatePublished, "datePublished", "dateCreated")
+	b.str("DateModified", &a.DateModified, "dateModified")
+	return a
+}
+
+func newRecipe(n *schemaNode, typ string) *Recipe {
+	r := &Recipe{}
+	b := newEntityBuilder(n, typ, &r.SchemaEntity)
+	b.str("Name", &r.Name, "name")
+	b.str("Description", &r.Description, "description")
+	b.str("Image", &r.Image, "image")
+	b.str("URL", &r.URL, "url")
+	b.strs("Authors", &r.Authors, "author")
+	b.str("PrepTime", &r.PrepTime, "prepTime")
+	b.str("CookTime", &r.CookTime, "cookTime")
+	b.str("TotalTime", &r.TotalTime, "totalTime")
+	b.str("Yield", &r.Yield, "recipeYield", "yield")
+	b.str("Category", &r.Category, "recipeCategory")
+	b.str("Cuisine", &r.Cuisine, "recipeCuisine")
+	b.strs("Ingredients", &r.Ingredients, "recipeIngredient", "ingredients")
+	r.Instructions = recipeSteps(n.props["recipeInstructions"], map[*schemaNode]bool{n: true})
+	if r.Instructions != nil {
+		b.e.Sources["Instructions"] = n.sources["recipeInstructions"]
+	}
+	return r
+}
+
+//Returns the texts of HowToStep objects, flattening HowToSection objects, or the instructions themselves if they are text.
+//seen holds the sections being flattened, sections that contain themselves are skipped.
+func recipeSteps(values []any, seen map[*schemaNode]bool) []string {
+	var steps []string
+	for _, v := range values {
+		c, ok := v.(*schemaNode)
+		if !ok {
+			if s := schemaText(v); s != "" {
+				steps = append(steps, s)
+			}
+			continue
+		}
+		if seen[c] {
+			continue
+		}
+		if items := c.props["itemListElement"]; items != nil {
+			seen[c] = true
+			steps = append(steps, recipeSteps(items, seen)...)
+			delete(seen, c)
+		} else if s, _ := c.str("text", "name"); s != "" {
+			steps = append(steps, s)
+		}
+	}
+	return steps
+}
+
+func newEvent(n *schemaNode, typ string) *Event {
+	e := &Event{}
+	b := newEntityBuilder(n, typ, &e.SchemaEntity)
+	b.str("Name", &e.Name, "name")
+	b.str("Description", &e.Description, "description")
+	b.str("Image", &e.Image, "image")
+	b.str("URL", &e.URL, "url")
+	b.str("StartDate", &e.StartDate, "startDate")
+	b.str("EndDate", &e.EndDate, "endDate")
+	b.enum("Status", &e.Status, "eventStatus")
+	b.str("Organizer", &e.Organizer, "organizer")
+	b.offers("Offers", &e.Offers)
+	for _, v := range n.props["location"] {
+		src := n.sources["location"]
+		loc, ok := v.(*schemaNode)
+		if !ok {
+			b.e.fill("Location", &e.Location, schemaText(v), src)
+			break
+		}
+		name, _ := loc.str("name")
+		b.e.fill("Location", &e.Location, name, src)
+		b.e.fill("Address", &e.Address, schemaAddress(loc), src)
+		break
+	}
+	return e
+}
+
+//Returns the address of a place as text.
+func schemaAddress(place *schemaNode) string {
+	for _, v := range place.props["address"] {
+		addr, ok := v.(*schemaNode)
+		if !ok {
+			return schemaText(v)
+		}
+		var parts []string
+		for _, p := range []string{"streetAddress", "postalCode", "addressLocality", "addressRegion", "addressCountry"} {
+			if s, _ := addr.str(p); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+	return ""
+}
+
+func newBreadcrumbList(n *schemaNode, typ string) *BreadcrumbList {
+	l := &BreadcrumbList{}
+	newEntityBuilder(n, typ, &l.SchemaEntity)
+	for i, item := range n.nodes("itemListElement") {
+		bi := BreadcrumbItem{Position: i + 1}
+		if pos, _ := item.str("position"); pos != "" {
+			if p, err := strconv.Atoi(pos); err == nil {
+				bi.Position = p
+			}
+		}
+		bi.Name, _ = item.str("name")
+		for _, v := range item.props["item"] {
+			if c, ok := v.(*schemaNode); ok {
+				if bi.Name == "" {
+					bi.Name, _ = c.str("name")
+				}
+				bi.URL = c.id
+				if u, _ := c.str("url"); u != "" {
+					bi.URL = u
+				}
+			} else {
+				bi.URL = schemaText(v)
+			}
+			break
+		}
+		if bi.URL == "" {
+			bi.URL, _ = item.str("url")
+		}
+		l.Items = append(l.Items, bi)
+		l.Sources["Items"] = n.sources["itemListElement"]
+	}
+	sort.SliceStable(l.Items, func(i, j int) bool {
+		return l.Items[i].Position < l.Items[j].Position
+	})
+	return l
+}
+
+//Fills missing fields of the only article and the only product from OpenGraph and meta elements,
+//or creates them if there are none and og:type asks for them.
+func (e *SchemaEntities) fallback(m *PageMetadata) {
+	og := &m.OpenGraph
+	ogType := strings.ToLower(og.Type)
+	prop := func(name string) string {
+		if v := m.Properties[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	image := ""
+	if len(og.Images) > 0 {
+		image = og.Images[0].URL
+	}
+
+	if len(e.Articles) == 0 && strings.HasPrefix(ogType, "article") {
+		e.Articles = append(e.Articles, &Article{SchemaEntity: SchemaEntity{Type: "Article", Sources: make(map[string]DataSource)}})
+	}
+	if len(e.Articles) == 1 {
+		a := e.Articles[0]
+		a.fill("Headline", &a.Headline, og.Title, SourceOpenGraph)
+		a.fill("Headline", &a.Headline, m.Title, SourceMeta)
+		a.fill("Description", &a.Description, og.Description, SourceOpenGraph)
+		a.fill("Description", &a.Description, m.Description, SourceMeta)
+		a.fill("Image", &a.Image, image, SourceOpenGraph)
+		a.fill("URL", &a.URL, og.URL, SourceOpenGraph)
+		a.fill("URL", &a.URL, m.Canonical, SourceMeta)
+		a.fill("Publisher", &a.Publisher, og.SiteName, SourceOpenGraph)
+		a.fill("DatePublished", &a.DatePublished, prop("article:published_time"), SourceOpenGraph)
+		a.fill("DateModified", &a.DateModified, prop("article:modified_time"), SourceOpenGraph)
+		if a.Authors == nil && m.Properties["article:author"] != nil {
+			a.Authors = m.Properties["article:author"]
+			a.Sources["Authors"] = SourceOpenGraph
+		}
+	}
+
+	if len(e.Products) == 0 && (ogType == "product" || strings.HasPrefix(ogType, "product.") || ogType == "og:product") {
+		e.Products = append(e.Products, &Product{SchemaEntity: SchemaEntity{Type: "Product", Sources: make(map[string]DataSource)}})
+	}
+	if len(e.Products) == 1 {
+		p := e.Products[0]
+		p.fill("Name", &p.Name, og.Title, SourceOpenGraph)
+		p.fill("Name", &p.Name, m.Title, SourceMeta)
+		p.fill("Description", &p.Description, og.Description, SourceOpenGraph)
+		p.fill("Description", &p.Description, m.Description, SourceMeta)
+		p.fill("Image", &p.Image, image, SourceOpenGraph)
+		p.fill("URL", &p.URL, og.URL, SourceOpenGraph)
+		p.fill("URL", &p.URL, m.Canonical, SourceMeta)
+		p.fill("Brand", &p.Brand, prop("product:brand"), SourceOpenGraph)
+		price := prop("product:price:amount")
+		if price == "" {
+			price = prop("og:price:amount")
+		}
+		if p.Offers == nil && price != "" {
+			currency := prop("product:price:currency")
+			if currency == "" {
+				currency = prop("og:price:currency")
+			}
+			p.Offers = []Offer{{Price: price, PriceCurrency: currency, Availability: localName(prop("product:availability"))}}
+			p.Sources["Offers"] = SourceOpenGraph
+		}
+	}
+}
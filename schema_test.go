@@ -0,0 +1,150 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaOrg(t *testing.T) {
+	const testDoc = "schema.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := SchemaOrg(root, nil)
+	if len(e.Products) != 1 || len(e.Articles) != 1 || len(e.Recipes) != 1 || len(e.Events) != 1 || len(e.Breadcrumbs) != 1 {
+		t.Fatalf("Unexpected number of entities: %d products, %d articles, %d recipes, %d events, %d breadcrumb lists",
+			len(e.Products), len(e.Articles), len(e.Recipes), len(e.Events), len(e.Breadcrumbs))
+	}
+
+	p := e.Products[0]
+	expectProduct := &Product{
+		SchemaEntity: SchemaEntity{
+			Type: "Product",
+			ID:   "https://example.org/shop/item#widget",
+			Sources: map[string]DataSource{
+				"Name":        SourceJSONLD,
+				"Image":       SourceJSONLD,
+				"Brand":       SourceJSONLD,
+				"Offers":      SourceJSONLD,
+				"Rating":      SourceJSONLD,
+				"RatingCount": SourceJSONLD,
+				"SKU":         SourceMicrodata,
+				"Description": SourceMicrodata,
+			},
+		},
+		Name:        "Widget",
+		Description: "A widget",
+		Image:       "https://example.org/widget.jpg",
+		SKU:         "W-1",
+		Brand:       "ACME",
+		Offers:      []Offer{{Price: "9.99", PriceCurrency: "EUR", Availability: "InStock"}},
+		Rating:      "4.5",
+		RatingCount: "12",
+	}
+	if !reflect.DeepEqual(p, expectProduct) {
+		t.Errorf("Expected product %+v, got %+v", expectProduct, p)
+	}
+
+	r := e.Recipes[0]
+	if !reflect.DeepEqual(r.Instructions, []string{"Mix.", "Rest.", "Fry."}) || !reflect.DeepEqual(r.Authors, []string{"A", "B"}) ||
+		!reflect.DeepEqual(r.Ingredients, []string{"Flour", "Milk"}) || r.Yield != "4" {
+		t.Errorf("Unexpected recipe %+v", r)
+	}
+
+	ev := e.Events[0]
+	if ev.Type != "MusicEvent" || ev.Name != "Concert" || ev.StartDate != "2021-07-01T20:00" || ev.Status != "EventScheduled" ||
+		ev.Location != "Hall" || ev.Address != "Main St 1, Town" || ev.Sources["Address"] != SourceRDFa {
+		t.Errorf("Unexpected event %+v", ev)
+	}
+
+	expectCrumbs := []BreadcrumbItem{{1, "Home", "https://example.org/"}, {2, "Tools", "https://example.org/shop/tools/"}}
+	if b := e.Breadcrumbs[0]; !reflect.DeepEqual(b.Items, expectCrumbs) || b.Sources["Items"] != SourceMicrodata {
+		t.Errorf("Expected breadcrumbs %v, got %v", expectCrumbs, b.Items)
+	}
+
+	a := e.Articles[0]
+	if a.Headline != "OG headline" || a.Description != "Meta description" || a.Image != "https://example.org/og.jpg" ||
+		a.DatePublished != "2021-06-01" || !reflect.DeepEqual(a.Authors, []string{"https://example.org/authors/jw"}) {
+		t.Errorf("Unexpected article %+v", a)
+	}
+	if a.Sources["Headline"] != SourceOpenGraph || a.Sources["Description"] != SourceMeta {
+		t.Errorf("Unexpected article sources %v", a.Sources)
+	}
+}
+
+func TestSchemaOrgFallback(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<title>Page</title>
+		<meta property="og:type" content="product">
+		<meta property="product:price:amount" content="5">
+		<meta property="product:price:currency" content="USD">
+		<script type="application/ld+json">{"@type": "NewsArticle", "headline": "News"}</script>
+		<script type="application/ld+json">{"@type": "NewsArticle", "headline": "More news"}</script>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := SchemaOrg(doc, nil)
+	if len(e.Products) != 1 || e.Products[0].Name != "Page" || e.Products[0].Sources["Name"] != SourceMeta {
+		t.Fatalf("Expected a product built from metadata, got %v", e.Products)
+	}
+	if o := e.Products[0].Offers; len(o) != 1 || o[0].Price != "5" || o[0].PriceCurrency != "USD" {
+		t.Errorf("Unexpected offers %v", o)
+	}
+	if len(e.Articles) != 2 || e.Articles[0].Description != "" || e.Articles[0].Type != "NewsArticle" {
+		t.Error("Metadata must not be applied if there are several articles")
+	}
+}
+
+func TestSchemaOrgCycles(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<script type="application/ld+json">[
+		{"@type": "Product", "@id": "#a", "name": {"@id": "#a"}, "brand": {"@id": "#a"}, "sku": "A"},
+		{"@type": "Recipe", "name": "Soup", "recipeInstructions": {"@id": "#s"}},
+		{"@type": "HowToSection", "@id": "#s", "itemListElement": [{"@type": "HowToStep", "text": "Stir"}, {"@id": "#s"}]}
+	]</script>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := SchemaOrg(doc, nil)
+	if len(e.Products) != 1 || e.Products[0].Name != "" || e.Products[0].Brand != "" || e.Products[0].SKU != "A" {
+		t.Errorf("Unexpected products %v", e.Products)
+	}
+	if len(e.Recipes) != 1 || !reflect.DeepEqual(e.Recipes[0].Instructions, []string{"Stir"}) {
+		t.Errorf("Unexpected recipes %v", e.Recipes)
+	}
+}
+
+func TestSchemaOrgMergeByName(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<script type="application/ld+json">[
+		{"@type": "Product", "@id": "#a", "name": "Shirt", "sku": "A"},
+		{"@type": "Product", "@id": "#b", "name": "Shirt", "sku": "B"},
+		{"@type": "Product", "name": "Hat", "sku": "C"}
+	]</script>
+	<div itemscope itemtype="https://schema.org/Product"><span itemprop="name">hat</span><span itemprop="gtin">1</span></div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := SchemaOrg(doc, nil).Products
+	if len(p) != 3 || p[0].SKU != "A" || p[1].SKU != "B" || p[2].SKU != "C" || p[2].GTIN != "1" {
+		t.Errorf("Unexpected products %v", p)
+	}
+}
+
+func TestSchemaOrgMergedReferences(t *testing.T) {
+	doc, err := html.Parse(strings.NewReader(`<script type="application/ld+json">
+		{"@type": "Place", "@id": "https://example.org/hall", "address": "Main St 1"}</script>
+	<div itemscope itemtype="https://schema.org/Event"><span itemprop="name">Concert</span>
+		<div itemprop="location" itemscope itemtype="https://schema.org/Place" itemid="https://example.org/hall"><span itemprop="name">Hall</span></div>
+	</div>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := SchemaOrg(doc, nil).Events
+	if len(e) != 1 || e[0].Location != "Hall" || e[0].Address != "Main St 1" {
+		t.Errorf("References must point to the merged entity, got %v", e)
+	}
+}
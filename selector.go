@@ -0,0 +1,793 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"fmt"
+	"github.com/jwdev42/rottensoup/internal/cond"
+	"github.com/jwdev42/rottensoup/internal/nav"
+	"golang.org/x/net/html"
+	"strconv"
+	"strings"
+)
+
+//Selector is a compiled group of CSS selectors as defined by Selectors Level 3.
+//It supports type, universal, id, class and attribute selectors, all four combinators,
+//the structural pseudo-classes, :not(), :lang(), :link and the UI state pseudo-classes.
+//Dynamic pseudo-classes like :hover never match, pseudo-elements are rejected.
+type Selector struct {
+	src    string
+	groups []complexSelector
+}
+
+//SelectorError describes a syntax error in a CSS selector.
+type SelectorError struct {
+	Selector string //the selector that failed to compile
+	Offset   int    //byte offset of the error within Selector
+	Msg      string
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid selector %q at offset %d: %s", e.Selector, e.Offset, e.Msg)
+}
+
+//CompileSelector parses a comma-separated group of CSS selectors.
+func CompileSelector(sel string) (*Selector, error) {
+	p := &selectorParser{src: sel}
+	groups, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	return &Selector{src: sel, groups: groups}, nil
+}
+
+//MustCompileSelector is like CompileSelector but panics if the selector cannot be parsed.
+func MustCompileSelector(sel string) *Selector {
+	s, err := CompileSelector(sel)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+//Returns the source text the selector was compiled from.
+func (s *Selector) String() string {
+	return s.src
+}
+
+//Returns true if n is an element that matches at least one selector of the group.
+func (s *Selector) Match(n *html.Node) bool {
+	if n == nil || n.Type != html.ElementNode {
+		return false
+	}
+	for i := range s.groups {
+		if s.groups[i].match(len(s.groups[i].compounds)-1, n) {
+			return true
+		}
+	}
+	return false
+}
+
+//Executes depth-first search on all descendants of n and returns the first element matched by the selector.
+//Node n itself is never returned. Returns nil if no such element was found.
+func (s *Selector) Query(n *html.Node) *html.Node {
+	nodes := make([]*html.Node, 0, 1)
+	nav.DFS(n, cond.Match(&nodes, true, s.descendantOf(n)), nil)
+	if len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+//Executes depth-first search on all descendants of n and returns all elements matched by the selector in document order.
+//Node n itself is never returned. Returns nil if no matches were found.
+func (s *Selector) QueryAll(n *html.Node) []*html.Node {
+	nodes := make([]*html.Node, 0, 10)
+	nav.DFS(n, cond.Match(&nodes, false, s.descendantOf(n)), nil)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes
+}
+
+func (s *Selector) descendantOf(root *html.Node) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return n != root && s.Match(n)
+	}
+}
+
+//Compiles sel and returns the first descendant of n it matches, see Selector.Query.
+//An error is returned if sel is not a valid selector.
+func Query(n *html.Node, sel string) (*html.Node, error) {
+	s, err := CompileSelector(sel)
+	if err != nil {
+		return nil, err
+	}
+	return s.Query(n), nil
+}
+
+//Compiles sel and returns all descendants of n it matches, see Selector.QueryAll.
+//An error is returned if sel is not a valid selector.
+func QueryAll(n *html.Node, sel string) ([]*html.Node, error) {
+	s, err := CompileSelector(sel)
+	if err != nil {
+		return nil, err
+	}
+	return s.QueryAll(n), nil
+}
+
+/* --- Matching --- */
+
+type simpleSelector func(*html.Node) bool
+
+type compoundSelector []simpleSelector
+
+func (c compoundSelector) match(n *html.Node) bool {
+	for _, s := range c {
+		if !s(n) {
+			return false
+		}
+	}
+	return true
+}
+
+//complexSelector is a chain of compound selectors, combinators[i] joins compounds[i] and compounds[i+1].
+type complexSelector struct {
+	compounds   []compoundSelector
+	combinators []byte
+}
+
+//Matches the selector chain from right to left, starting at compound i.
+func (c *complexSelector) match(i int, n *html.Node) bool {
+	if !c.compounds[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch c.combinators[i-1] {
+	case '>':
+		p := parentElement(n)
+		return p != nil && c.match(i-1, p)
+	case ' ':
+		for p := parentElement(n); p != nil; p = parentElement(p) {
+			if c.match(i-1, p) {
+				return true
+			}
+		}
+	case '+':
+		s := PrevElementSibling(n)
+		return s != nil && c.match(i-1, s)
+	case '~':
+		for s := PrevElementSibling(n); s != nil; s = PrevElementSibling(s) {
+			if c.match(i-1, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parentElement(n *html.Node) *html.Node {
+	if n.Parent != nil && n.Parent.Type == html.ElementNode {
+		return n.Parent
+	}
+	return nil
+}
+
+func attrValFold(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && strings.EqualFold(a.Key, key) {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func matchType(name string) simpleSelector {
+	return func(n *html.Node) bool {
+		return strings.EqualFold(n.Data, name)
+	}
+}
+
+func matchAttr(key, op, val string) simpleSelector {
+	return func(n *html.Node) bool {
+		v, ok := attrValFold(n, key)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "":
+			return true
+		case "=":
+			return v == val
+		case "~=":
+			if val == "" || strings.ContainsAny(val, " \t\n\f\r") {
+				return false
+			}
+			for _, f := range cond.Tokens(v) {
+				if f == val {
+					return true
+				}
+			}
+			return false
+		case "|=":
+			return v == val || strings.HasPrefix(v, val+"-")
+		case "^=":
+			return val != "" && strings.HasPrefix(v, val)
+		case "$=":
+			return val != "" && strings.HasSuffix(v, val)
+		case "*=":
+			return val != "" && strings.Contains(v, val)
+		}
+		return false
+	}
+}
+
+//Returns a matcher for :nth-child(an+b) and its relatives.
+//If ofType is true, only siblings of the same element type are counted, if last is true, counting starts at the last sibling.
+func matchNth(a, b int, ofType, last bool) simpleSelector {
+	return func(n *html.Node) bool {
+		i := 1
+		next := PrevElementSibling
+		if last {
+			next = NextElementSibling
+		}
+		for s := next(n); s != nil; s = next(s) {
+			if !ofType || s.Data == n.Data && s.Namespace == n.Namespace {
+				i++
+			}
+		}
+		if a == 0 {
+			return i == b
+		}
+		return (i-b)/a >= 0 && (i-b)%a == 0
+	}
+}
+
+func matchOnly(ofType bool) simpleSelector {
+	first := matchNth(0, 1, ofType, false)
+	last := matchNth(0, 1, ofType, true)
+	return func(n *html.Node) bool {
+		return first(n) && last(n)
+	}
+}
+
+func matchRoot(n *html.Node) bool {
+	return n.Parent == nil || n.Parent.Type == html.DocumentNode
+}
+
+func matchEmpty(n *html.Node) bool {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode || c.Type == html.TextNode && c.Data != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func matchLink(n *html.Node) bool {
+	if n.Namespace != "" {
+		return false
+	}
+	switch n.Data {
+	case "a", "area", "link":
+		_, ok := attrValFold(n, "href")
+		return ok
+	}
+	return false
+}
+
+func matchChecked(n *html.Node) bool {
+	if n.Namespace != "" {
+		return false
+	}
+	switch n.Data {
+	case "input":
+		t, _ := attrValFold(n, "type")
+		if t = strings.ToLower(t); t != "checkbox" && t != "radio" {
+			return false
+		}
+		_, ok := attrValFold(n, "checked")
+		return ok
+	case "option":
+		_, ok := attrValFold(n, "selected")
+		return ok
+	}
+	return false
+}
+
+func isFormControl(n *html.Node) bool {
+	if n.Namespace != "" {
+		return false
+	}
+	switch n.Data {
+	case "button", "input", "select", "textarea", "optgroup", "option", "fieldset":
+		return true
+	}
+	return false
+}
+
+//Reports whether n is disabled, either by its own disabled attribute or by a disabled ancestor
+//fieldset or optgroup. Descendants of a disabled fieldset's first legend stay enabled.
+func matchDisabled(n *html.Node) bool {
+	if !isFormControl(n) {
+		return false
+	}
+	if _, ok := attrValFold(n, "disabled"); ok {
+		return true
+	}
+	child := n
+	for p := parentElement(n); p != nil; child, p = p, parentElement(p) {
+		if p.Namespace != "" {
+			continue
+		}
+		_, disabled := attrValFold(p, "disabled")
+		switch {
+		case !disabled:
+		case p.Data == "optgroup" && n.Data == "option":
+			return true
+		case p.Data == "fieldset":
+			legend := p.FirstChild
+			for legend != nil && !(legend.Type == html.ElementNode && legend.Data == "legend") {
+				legend = legend.NextSibling
+			}
+			return child != legend
+		}
+	}
+	return false
+}
+
+func matchEnabled(n *html.Node) bool {
+	return isFormControl(n) && !matchDisabled(n)
+}
+
+func matchLang(lang string) simpleSelector {
+	return func(n *html.Node) bool {
+		for e := n; e != nil; e = e.Parent {
+			if e.Type != html.ElementNode {
+				continue
+			}
+			if v, ok := attrValFold(e, "lang"); ok {
+				return strings.EqualFold(v, lang) || len(v) > len(lang) && strings.EqualFold(v[:len(lang)+1], lang+"-")
+			}
+		}
+		return false
+	}
+}
+
+func matchNone(*html.Node) bool {
+	return false
+}
+
+/* --- Parsing --- */
+
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) errorf(format string, a ...interface{}) error {
+	return &SelectorError{Selector: p.src, Offset: p.pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+func (p *selectorParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *selectorParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+//Skips whitespace and reports whether any was found.
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.eof() && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '-'
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func (p *selectorParser) parseGroup() ([]complexSelector, error) {
+	groups := make([]complexSelector, 0, 1)
+	for {
+		p.skipSpace()
+		c, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, c)
+		if p.eof() {
+			return groups, nil
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("unexpected %q", p.peek())
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	var c complexSelector
+	for {
+		compound, err := p.parseCompound()
+		if err != nil {
+			return c, err
+		}
+		c.compounds = append(c.compounds, compound)
+		space := p.skipSpace()
+		if p.eof() || p.peek() == ',' || p.peek() == ')' {
+			return c, nil
+		}
+		switch comb := p.peek(); comb {
+		case '>', '+', '~':
+			p.pos++
+			p.skipSpace()
+			c.combinators = append(c.combinators, comb)
+		default:
+			if !space {
+				return c, p.errorf("unexpected %q", comb)
+			}
+			c.combinators = append(c.combinators, ' ')
+		}
+	}
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	compound := make(compoundSelector, 0, 2)
+	if p.peek() == '*' {
+		p.pos++
+		compound = append(compound, func(*html.Node) bool { return true })
+	} else if p.startsIdent() {
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		compound = append(compound, matchType(name))
+	}
+	if p.peek() == '|' {
+		return nil, p.errorf("namespace prefixes are not supported")
+	}
+	for !p.eof() {
+		var s simpleSelector
+		var err error
+		switch p.peek() {
+		case '#':
+			p.pos++
+			var id string
+			if id, err = p.parseName(); err == nil {
+				s = matchAttr("id", "=", id)
+			}
+		case '.':
+			p.pos++
+			var class string
+			if class, err = p.parseIdent(); err == nil {
+				s = matchAttr("class", "~=", class)
+			}
+		case '[':
+			s, err = p.parseAttr()
+		case ':':
+			s, err = p.parsePseudo()
+		default:
+			if len(compound) == 0 {
+				return nil, p.errorf("expected selector")
+			}
+			return compound, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		compound = append(compound, s)
+	}
+	if len(compound) == 0 {
+		return nil, p.errorf("expected selector")
+	}
+	return compound, nil
+}
+
+func (p *selectorParser) parseAttr() (simpleSelector, error) {
+	p.pos++ //skip '['
+	p.skipSpace()
+	key, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() == '|' && !strings.HasPrefix(p.src[p.pos:], "|=") {
+		return nil, p.errorf("namespace prefixes are not supported")
+	}
+	if p.peek() == ']' {
+		p.pos++
+		return matchAttr(key, "", ""), nil
+	}
+	var op string
+	switch c := p.peek(); c {
+	case '=':
+		op = "="
+	case '~', '|', '^', '$', '*':
+		if !strings.HasPrefix(p.src[p.pos:], string(c)+"=") {
+			return nil, p.errorf("expected attribute operator")
+		}
+		op = string(c) + "="
+	default:
+		return nil, p.errorf("expected attribute operator")
+	}
+	p.pos += len(op)
+	p.skipSpace()
+	var val string
+	if c := p.peek(); c == '"' || c == '\'' {
+		val, err = p.parseString()
+	} else {
+		val, err = p.parseName()
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return nil, p.errorf("expected ']'")
+	}
+	p.pos++
+	return matchAttr(key, op, val), nil
+}
+
+func (p *selectorParser) parsePseudo() (simpleSelector, error) {
+	p.pos++ //skip ':'
+	if p.peek() == ':' {
+		return nil, p.errorf("pseudo-elements are not supported")
+	}
+	start := p.pos
+	name, err := p.parseIdent()
+	if err != nil {
+		return nil, err
+	}
+	name = strings.ToLower(name)
+	if p.peek() != '(' {
+		switch name {
+		case "root":
+			return matchRoot, nil
+		case "empty":
+			return matchEmpty, nil
+		case "first-child":
+			return matchNth(0, 1, false, false), nil
+		case "last-child":
+			return matchNth(0, 1, false, true), nil
+		case "only-child":
+			return matchOnly(false), nil
+		case "first-of-type":
+			return matchNth(0, 1, true, false), nil
+		case "last-of-type":
+			return matchNth(0, 1, true, true), nil
+		case "only-of-type":
+			return matchOnly(true), nil
+		case "link", "any-link":
+			return matchLink, nil
+		case "checked":
+			return matchChecked, nil
+		case "disabled":
+			return matchDisabled, nil
+		case "enabled":
+			return matchEnabled, nil
+		case "visited", "hover", "active", "focus", "target":
+			return matchNone, nil
+		case "before", "after", "first-line", "first-letter":
+			p.pos = start
+			return nil, p.errorf("pseudo-elements are not supported")
+		}
+		p.pos = start
+		return nil, p.errorf("unknown pseudo-class %q", name)
+	}
+	p.pos++ //skip '('
+	p.skipSpace()
+	var s simpleSelector
+	switch name {
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		var a, b int
+		if a, b, err = p.parseNth(); err != nil {
+			return nil, err
+		}
+		s = matchNth(a, b, strings.HasSuffix(name, "of-type"), strings.HasPrefix(name, "nth-last"))
+	case "not":
+		var compound compoundSelector
+		if compound, err = p.parseCompound(); err != nil {
+			return nil, err
+		}
+		s = func(n *html.Node) bool {
+			return !compound.match(n)
+		}
+	case "lang":
+		var lang string
+		if lang, err = p.parseIdent(); err != nil {
+			return nil, err
+		}
+		s = matchLang(lang)
+	default:
+		p.pos = start
+		return nil, p.errorf("unknown pseudo-class %q", name)
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf("expected ')'")
+	}
+	p.pos++
+	return s, nil
+}
+
+//Parses the an+b notation of the :nth-* pseudo-classes up to the closing parenthesis.
+func (p *selectorParser) parseNth() (a, b int, err error) {
+	end := strings.IndexByte(p.src[p.pos:], ')')
+	if end < 0 {
+		return 0, 0, p.errorf("expected ')'")
+	}
+	expr := strings.ToLower(strings.TrimSpace(p.src[p.pos : p.pos+end]))
+	invalid := p.errorf("invalid nth expression %q", expr)
+	p.pos += end
+	switch expr {
+	case "odd":
+		return 2, 1, nil
+	case "even":
+		return 2, 0, nil
+	}
+	i := strings.IndexByte(expr, 'n')
+	if i < 0 {
+		if b, err = parseSignedInt(expr); err != nil {
+			return 0, 0, invalid
+		}
+		return 0, b, nil
+	}
+	switch coeff := expr[:i]; coeff {
+	case "", "+":
+		a = 1
+	case "-":
+		a = -1
+	default:
+		if a, err = parseSignedInt(coeff); err != nil {
+			return 0, 0, invalid
+		}
+	}
+	rest := strings.TrimSpace(expr[i+1:])
+	if rest == "" {
+		return a, 0, nil
+	}
+	if rest[0] != '+' && rest[0] != '-' {
+		return 0, 0, invalid
+	}
+	digits := strings.TrimSpace(rest[1:])
+	if digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return 0, 0, invalid
+	}
+	if b, err = strconv.Atoi(digits); err != nil {
+		return 0, 0, invalid
+	}
+	if rest[0] == '-' {
+		b = -b
+	}
+	return a, b, nil
+}
+
+func parseSignedInt(s string) (int, error) {
+	if s == "" || strings.ContainsAny(s, " \t\n\r\f") {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.Atoi(s)
+}
+
+func (p *selectorParser) startsIdent() bool {
+	rest := p.src[p.pos:]
+	if strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	}
+	return rest != "" && (isNameStart(rest[0]) || rest[0] == '\\' || rest[0] == '-')
+}
+
+//Parses a CSS identifier.
+func (p *selectorParser) parseIdent() (string, error) {
+	if !p.startsIdent() {
+		return "", p.errorf("expected identifier")
+	}
+	return p.parseName()
+}
+
+//Parses a sequence of name characters, which unlike an identifier may start with a digit.
+func (p *selectorParser) parseName() (string, error) {
+	var b strings.Builder
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(r)
+		case isNameChar(c):
+			b.WriteByte(c)
+			p.pos++
+		default:
+			if b.Len() == 0 {
+				return "", p.errorf("expected name")
+			}
+			return b.String(), nil
+		}
+	}
+	if b.Len() == 0 {
+		return "", p.errorf("expected name")
+	}
+	return b.String(), nil
+}
+
+//Parses a backslash escape sequence, p.pos must point at the backslash.
+func (p *selectorParser) parseEscape() (string, error) {
+	p.pos++
+	if p.eof() || p.peek() == '\n' || p.peek() == '\r' || p.peek() == '\f' {
+		return "", p.errorf("invalid escape sequence")
+	}
+	if !isHex(p.peek()) {
+		r := p.src[p.pos : p.pos+1]
+		p.pos++
+		return r, nil
+	}
+	start := p.pos
+	for !p.eof() && p.pos-start < 6 && isHex(p.peek()) {
+		p.pos++
+	}
+	code, _ := strconv.ParseUint(p.src[start:p.pos], 16, 32)
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if !p.eof() && isSpace(p.peek()) {
+		p.pos++
+	}
+	if code == 0 || code > 0x10ffff || code >= 0xd800 && code <= 0xdfff {
+		code = 0xfffd
+	}
+	return string(rune(code)), nil
+}
+
+func (p *selectorParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && strings.HasPrefix(p.src[p.pos+1:], "\r\n"):
+			p.pos += 3
+		case c == '\\' && p.pos+1 < len(p.src) && (p.src[p.pos+1] == '\n' || p.src[p.pos+1] == '\f' || p.src[p.pos+1] == '\r'):
+			p.pos += 2
+		case c == '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(r)
+		case c == '\n':
+			return "", p.errorf("unterminated string")
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
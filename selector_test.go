@@ -0,0 +1,127 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQueryAll(t *testing.T) {
+	const testDoc = "selector.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sel    string
+		expect string //space-separated ids of the expected elements
+	}{
+		{"h2", "h1 h2"},
+		{"#a3", "a3"},
+		{"div.card > a[href^=https]", "a1 a3"},
+		{"div.card.featured a", "a1 a2"},
+		{".special", "c3"},
+		{"a[href$='.pdf']", "a3"},
+		{"a[href*=example]:not([href^=http\\:])", "a1 a3"},
+		{"[lang|=de]", "p1"},
+		{"p:lang(de)", "p1"},
+		{"[class~=featured]", "c1"},
+		{"h2 + a", "a1"},
+		{"h2 ~ a", "a1 a2 a3"},
+		{"#cards > *", "c1 c2 c3"},
+		{"li:nth-child(odd)", "l1 l3 l5"},
+		{"li:nth-child(2n)", "l2 l4"},
+		{"li:nth-child(-n + 2)", "l1 l2"},
+		{"li:nth-last-child(2)", "l4"},
+		{"li:first-child, li:last-child", "l1 l5"},
+		{"a:nth-of-type(2)", "a2"},
+		{"#c2 > :last-of-type", "h2 p1 a3"},
+		{"span:empty", "empty"},
+		{"span:only-of-type", ""},
+		{"a:only-of-type", "a3"},
+		{"input:checked", "i1"},
+		{"input:disabled", "i2 i4"},
+		{"input:enabled", "i1 i3"},
+		{"html:root", ""},
+		{"a:hover", ""},
+		{"LI#L1", ""},
+		{"UL > LI#l1", "l1"},
+	}
+
+	for _, test := range tests {
+		nodes, err := QueryAll(root, test.sel)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.sel, err)
+			continue
+		}
+		ids := make([]string, len(nodes))
+		for i, n := range nodes {
+			ids[i] = AttrVal(n, "", "id")
+		}
+		if got := strings.Join(ids, " "); got != test.expect {
+			t.Errorf("%s: expected \"%s\", got \"%s\"", test.sel, test.expect, got)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	const testDoc = "selector.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := Query(root, "html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == nil || !MustCompileSelector(":root").Match(n) {
+		t.Error("Expected the html element to match :root")
+	}
+
+	card := ElementByID(root, "c1")
+	if q, _ := Query(card, "div"); q != nil {
+		t.Error("Query must not return the node it was called on")
+	}
+	if q, _ := Query(card, "div a"); q == nil || AttrVal(q, "", "id") != "a1" {
+		t.Error("Expected Query to return the first matching descendant")
+	}
+	if q, _ := Query(root, "table"); q != nil {
+		t.Error("Expected return value nil")
+	}
+}
+
+func TestCompileSelector(t *testing.T) {
+	invalid := []string{
+		"",
+		"a,",
+		"a >",
+		"div..card",
+		"[href",
+		"[href=]",
+		"[href=='x']",
+		"a::before",
+		"a:unknown",
+		"li:nth-child(2n+)",
+		"li:nth-child(n-)",
+		"svg|rect",
+		"a[title='unterminated]",
+	}
+	for _, sel := range invalid {
+		_, err := CompileSelector(sel)
+		var selErr *SelectorError
+		if !errors.As(err, &selErr) {
+			t.Errorf("%q: expected a *SelectorError, got %v", sel, err)
+		}
+	}
+
+	const src = "div.card > a[href^=https]"
+	if s := MustCompileSelector(src).String(); s != src {
+		t.Errorf("Expected \"%s\", got \"%s\"", src, s)
+	}
+}
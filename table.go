@@ -0,0 +1,380 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//ErrNotTable is returned by NewTable if the given node is not a table element.
+var ErrNotTable = errors.New("node is not a table element")
+
+//Table is the rectangular grid of cells described by a table element.
+//Rows of thead sections come first and rows of tfoot sections last, regardless of their position in the source.
+type Table struct {
+	Node     *html.Node
+	Caption  string
+	Rows     [][]*TableCell //all rows have the same length, a cell spanning several slots appears in each of them
+	HeadRows int            //number of leading rows that form the table header
+	FootRows int            //number of trailing rows that form the table footer
+}
+
+//TableCell is a cell of a Table.
+type TableCell struct {
+	Node    *html.Node //the td or th element, nil for slots that are not covered by any cell
+	Text    string     //rendered text of the cell, nested tables are excluded
+	Header  bool       //true if the cell is a th element
+	Row     int        //row of the cell's top left slot
+	Col     int        //column of the cell's top left slot
+	RowSpan int        //number of rows the cell covers after span processing
+	ColSpan int        //number of columns the cell covers after span processing
+}
+
+//Parses the table element n into a Table by applying the HTML table processing model, which resolves rowspan and colspan.
+//Rows of nested tables do not become part of the returned table. If the table has no thead section,
+//leading rows that consist of th elements only are treated as header rows.
+//Returns ErrNotTable if n is not a table element.
+func NewTable(n *html.Node) (*Table, error) {
+	if !isHTMLElement(n) || n.DataAtom != atom.Table {
+		return nil, ErrNotTable
+	}
+	t := &Table{Node: n}
+	var head, body, foot []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !isHTMLElement(c) {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Caption:
+			if t.Caption == "" {
+				t.Caption = InnerText(c)
+			}
+		case atom.Thead:
+			head = append(head, c)
+		case atom.Tfoot:
+			foot = append(foot, c)
+		case atom.Tbody, atom.Tr:
+			body = append(body, c)
+		}
+	}
+
+	b := &tableBuilder{}
+	for _, g := range head {
+		b.rowGroup(g)
+	}
+	t.HeadRows = b.height
+	for i := 0; i < len(body); i++ {
+		if body[i].DataAtom == atom.Tbody {
+			b.rowGroup(body[i])
+			continue
+		}
+		//consecutive rows outside of a row group form an implicit one
+		j := i
+		for j < len(body) && body[j].DataAtom == atom.Tr {
+			j++
+		}
+		for k := i; k < j; k++ {
+			b.row(body[k], j-k)
+		}
+		b.endRowGroup()
+		i = j - 1
+	}
+	bodyEnd := b.height
+	for _, g := range foot {
+		b.rowGroup(g)
+	}
+	t.FootRows = b.height - bodyEnd
+	t.Rows = b.rectangular()
+
+	if t.HeadRows == 0 {
+		for _, row := range t.Rows[:bodyEnd] {
+			if !allHeaderCells(row) {
+				break
+			}
+			t.HeadRows++
+		}
+	}
+	return t, nil
+}
+
+//Parses every table element in the subtree of n, including nested tables, see NewTable.
+//Returns nil if no tables were found.
+func Tables(n *html.Node) []*Table {
+	var tables []*Table
+	for _, e := range ElementsByTag(n, atom.Table) {
+		t, err := NewTable(e)
+		if err == nil {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+func allHeaderCells(row []*TableCell) bool {
+	found := false
+	for _, c := range row {
+		if c.Node == nil {
+			continue
+		}
+		if !c.Header {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+//Returns the text of all cells, row by row.
+func (t *Table) Strings() [][]string {
+	rows := make([][]string, len(t.Rows))
+	for i, row := range t.Rows {
+		rows[i] = make([]string, len(row))
+		for j, c := range row {
+			rows[i][j] = c.Text
+		}
+	}
+	return rows
+}
+
+//Returns one key per column, built from the texts of the header rows. Texts of stacked header rows are joined by a space.
+//Columns without header text are named "colN", where N is the column number starting at 1. Repeated keys get the suffix "_N",
+//where N is the column number or the next higher number that makes the key unique.
+//The keys are unique, so they can be used to address the values returned by Records.
+func (t *Table) Header() []string {
+	if len(t.Rows) == 0 {
+		return nil
+	}
+	keys := make([]string, len(t.Rows[0]))
+	seen := make(map[string]bool, len(keys))
+	for col := range keys {
+		parts := make([]string, 0, t.HeadRows)
+		var prev *TableCell
+		for _, row := range t.Rows[:t.HeadRows] {
+			c := row[col]
+			if c != prev && c.Text != "" && (len(parts) == 0 || parts[len(parts)-1] != c.Text) {
+				parts = append(parts, c.Text)
+			}
+			prev = c
+		}
+		key := strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+		if key == "" {
+			key = fmt.Sprintf("col%d", col+1)
+		}
+		if seen[key] {
+			n := col + 1
+			for seen[fmt.Sprintf("%s_%d", key, n)] {
+				n++
+			}
+			key = fmt.Sprintf("%s_%d", key, n)
+		}
+		seen[key] = true
+		keys[col] = key
+	}
+	return keys
+}
+
+//Returns the rows between header and footer as maps from the keys returned by Header to the cell texts.
+//Returns nil if the table has no such rows.
+func (t *Table) Records() []map[string]string {
+	keys := t.Header()
+	var records []map[string]string
+	for _, row := range t.Rows[t.HeadRows : len(t.Rows)-t.FootRows] {
+		rec := make(map[string]string, len(keys))
+		for i, c := range row {
+			rec[keys[i]] = c.Text
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+//Writes all rows of the table to w in CSV format.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(t.Strings()); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+//tableBuilder implements the table forming algorithm of the HTML standard.
+type tableBuilder struct {
+	slots    [][]*TableCell
+	width    int
+	height   int
+	ycurrent int
+	growing  []*TableCell //cells with rowspan 0 that grow until the end of their row group
+}
+
+func (b *tableBuilder) slot(x, y int) *TableCell {
+	if y >= len(b.slots) || x >= len(b.slots[y]) {
+		return nil
+	}
+	return b.slots[y][x]
+}
+
+func (b *tableBuilder) setSlot(x, y int, c *TableCell) {
+	for len(b.slots) <= y {
+		b.slots = append(b.slots, nil)
+	}
+	for len(b.slots[y]) <= x {
+		b.slots[y] = append(b.slots[y], nil)
+	}
+	if b.slots[y][x] == nil {
+		b.slots[y][x] = c
+	}
+}
+
+func (b *tableBuilder) rowGroup(g *html.Node) {
+	var rows []*html.Node
+	for c := g.FirstChild; c != nil; c = c.NextSibling {
+		if isHTMLElement(c) && c.DataAtom == atom.Tr {
+			rows = append(rows, c)
+		}
+	}
+	for i, tr := range rows {
+		b.row(tr, len(rows)-i)
+	}
+	b.endRowGroup()
+}
+
+func (b *tableBuilder) endRowGroup() {
+	for b.ycurrent < b.height {
+		b.grow()
+		b.ycurrent++
+	}
+	b.growing = nil
+}
+
+func (b *tableBuilder) grow() {
+	for _, c := range b.growing {
+		if c.Row+c.RowSpan > b.ycurrent {
+			continue
+		}
+		for x := c.Col; x < c.Col+c.ColSpan; x++ {
+			b.setSlot(x, b.ycurrent, c)
+		}
+		c.RowSpan++
+	}
+}
+
+//Adds the row tr, left is the number of rows of the current row group starting with tr.
+//Like in browsers, rowspan does not extend a cell beyond the end of its row group.
+func (b *tableBuilder) row(tr *html.Node, left int) {
+	if b.height == b.ycurrent {
+		b.height++
+	}
+	xcurrent := 0
+	b.grow()
+	for td := tr.FirstChild; td != nil; td = td.NextSibling {
+		if !isHTMLElement(td) || td.DataAtom != atom.Td && td.DataAtom != atom.Th {
+			continue
+		}
+		for xcurrent < b.width && b.slot(xcurrent, b.ycurrent) != nil {
+			xcurrent++
+		}
+		if xcurrent == b.width {
+			b.width++
+		}
+		colspan := spanAttr(td, "colspan", 1, 1000)
+		if colspan == 0 {
+			colspan = 1
+		}
+		rowspan := spanAttr(td, "rowspan", 1, 65534)
+		growing := rowspan == 0
+		if growing {
+			rowspan = 1
+		} else if rowspan > left {
+			rowspan = left
+		}
+		if b.width < xcurrent+colspan {
+			b.width = xcurrent + colspan
+		}
+		if b.height < b.ycurrent+rowspan {
+			b.height = b.ycurrent + rowspan
+		}
+		cell := &TableCell{
+			Node:    td,
+			Text:    cellText(td),
+			Header:  td.DataAtom == atom.Th,
+			Row:     b.ycurrent,
+			Col:     xcurrent,
+			RowSpan: rowspan,
+			ColSpan: colspan,
+		}
+		for y := b.ycurrent; y < b.ycurrent+rowspan; y++ {
+			for x := xcurrent; x < xcurrent+colspan; x++ {
+				b.setSlot(x, y, cell)
+			}
+		}
+		if growing {
+			b.growing = append(b.growing, cell)
+		}
+		xcurrent += colspan
+	}
+	b.ycurrent++
+}
+
+//Returns the grid with all rows padded to the table width, uncovered slots are filled with empty cells.
+func (b *tableBuilder) rectangular() [][]*TableCell {
+	rows := make([][]*TableCell, b.height)
+	for y := range rows {
+		rows[y] = make([]*TableCell, b.width)
+		for x := range rows[y] {
+			if c := b.slot(x, y); c != nil {
+				rows[y][x] = c
+			} else {
+				rows[y][x] = &TableCell{Row: y, Col: x, RowSpan: 1, ColSpan: 1}
+			}
+		}
+	}
+	return rows
+}
+
+//Parses a colspan or rowspan attribute, returns def if the attribute is missing or invalid and clamps the value to limit.
+func spanAttr(n *html.Node, key string, def, limit int) int {
+	v, ok := attrValFold(n, key)
+	if !ok {
+		return def
+	}
+	v = strings.TrimLeft(v, asciiWhitespace)
+	end := 0
+	for end < len(v) && v[end] >= '0' && v[end] <= '9' {
+		end++
+	}
+	i, err := strconv.Atoi(v[:end])
+	if err != nil {
+		return def
+	}
+	if i > limit {
+		return limit
+	}
+	return i
+}
+
+//Returns the rendered text of a table cell without the contents of nested tables.
+func cellText(cell *html.Node) string {
+	c := &textCollector{lineStart: true}
+	nested := func(n *html.Node) bool {
+		return n != cell && isHTMLElement(n) && n.DataAtom == atom.Table
+	}
+	Walk(cell, func(n *html.Node) WalkAction {
+		if nested(n) {
+			return SkipChildren
+		}
+		return c.enter(n)
+	}, func(n *html.Node) WalkAction {
+		if n == cell || nested(n) {
+			return Continue
+		}
+		return c.leave(n)
+	})
+	return c.b.String()
+}
@@ -0,0 +1,151 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"bytes"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewTable(t *testing.T) {
+	const testDoc = "table.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, err := NewTable(ElementByID(root, "prices"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Caption != "Price list" {
+		t.Errorf("Expected caption \"Price list\", got %q", table.Caption)
+	}
+	expect := [][]string{
+		{"Product", "Price", "Price"},
+		{"Product", "Min", "Max"},
+		{"Apple, \"red\"", "1", "2"},
+		{"Apple, \"red\"", "unknown", "unknown"},
+		{"Pear", "3", ""},
+		{"Total", "Total", "30"},
+	}
+	if got := table.Strings(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+	if table.HeadRows != 2 || table.FootRows != 1 {
+		t.Errorf("Expected 2 header rows and 1 footer row, got %d and %d", table.HeadRows, table.FootRows)
+	}
+	apple := table.Rows[2][0]
+	if apple != table.Rows[3][0] || apple.RowSpan != 2 || apple.ColSpan != 1 || apple.Row != 2 || apple.Col != 0 {
+		t.Error("Spanning cells must be shared by all slots they cover")
+	}
+	if !table.Rows[0][1].Header || table.Rows[2][1].Header || table.Rows[4][2].Node != nil {
+		t.Error("Unexpected cell properties")
+	}
+
+	if _, err := NewTable(FirstElementByTag(root, atom.Body)); err != ErrNotTable {
+		t.Errorf("Expected ErrNotTable, got %v", err)
+	}
+	if tables := Tables(root); len(tables) != 4 || tables[1].Node != ElementByID(root, "nested") {
+		t.Error("Tables should return all tables in document order")
+	}
+}
+
+func TestTableRecords(t *testing.T) {
+	const testDoc = "table.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prices, _ := NewTable(ElementByID(root, "prices"))
+	if h := prices.Header(); !reflect.DeepEqual(h, []string{"Product", "Price Min", "Price Max"}) {
+		t.Errorf("Unexpected header %q", h)
+	}
+	records := prices.Records()
+	if len(records) != 3 || records[0]["Price Max"] != "2" || records[1]["Product"] != "Apple, \"red\"" || records[2]["Price Min"] != "3" {
+		t.Errorf("Unexpected records %v", records)
+	}
+
+	plain, _ := NewTable(ElementByID(root, "plain"))
+	if plain.HeadRows != 1 {
+		t.Errorf("Expected a detected header row, got %d", plain.HeadRows)
+	}
+	if h := plain.Header(); !reflect.DeepEqual(h, []string{"Name", "col2", "Name_3"}) {
+		t.Errorf("Unexpected header %q", h)
+	}
+	expect := [][]string{{"Name", "", "Name"}, {"a", "b", ""}, {"a", "c", "d"}}
+	if got := plain.Strings(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("rowspan=0: expected %q, got %q", expect, got)
+	}
+
+	noheader, _ := NewTable(ElementByID(root, "noheader"))
+	if noheader.HeadRows != 0 {
+		t.Error("Rows with data cells must not be detected as header")
+	}
+	if r := noheader.Records(); len(r) != 1 || r[0]["col1"] != "Key" || r[0]["col2"] != "Value" {
+		t.Errorf("Unexpected records %v", r)
+	}
+}
+
+func TestTableWriteCSV(t *testing.T) {
+	const testDoc = "table.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, _ := NewTable(ElementByID(root, "prices"))
+	var b bytes.Buffer
+	if err := table.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	const expect = "Product,Price,Price\nProduct,Min,Max\n\"Apple, \"\"red\"\"\",1,2\n\"Apple, \"\"red\"\"\",unknown,unknown\nPear,3,\nTotal,Total,30\n"
+	if b.String() != expect {
+		t.Errorf("Expected %q, got %q", expect, b.String())
+	}
+}
+
+func TestTableRowSpanLimit(t *testing.T) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader("<table><thead><tr><th rowspan=65534 colspan=1000>a</th></tr></thead>"+
+		"<tbody><tr><td rowspan=5>b</td><td>c</td></tr><tr><td>d</td></tr></tbody></table>"), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := NewTable(nodes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 3 || table.HeadRows != 1 || len(table.Rows[0]) != 1000 {
+		t.Fatalf("Expected 3 rows and 1000 columns, got %d rows", len(table.Rows))
+	}
+	if a, b := table.Rows[0][0], table.Rows[1][0]; a.RowSpan != 1 || b.RowSpan != 2 || table.Rows[2][0] != b {
+		t.Error("rowspan must not extend beyond the row group")
+	}
+	if d := table.Rows[2][1]; d.Text != "d" {
+		t.Errorf("Expected cell \"d\", got %q", d.Text)
+	}
+}
+
+func TestTableHeaderSuffix(t *testing.T) {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader("<table><tr><th>a_3</th><th>a</th><th>a</th><th>a_2</th><th>a</th></tr></table>"), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := NewTable(nodes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := table.Header(); !reflect.DeepEqual(h, []string{"a_3", "a", "a_4", "a_2", "a_5"}) {
+		t.Errorf("Unexpected header %q", h)
+	}
+}
@@ -0,0 +1,253 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+)
+
+//Returns the concatenated data of all text nodes in the subtree of n, like the DOM's textContent.
+//Comments are ignored and no whitespace is altered.
+func TextContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for d := range Descendants(n) {
+		if d.Type == html.TextNode {
+			b.WriteString(d.Data)
+		}
+	}
+	return b.String()
+}
+
+//Returns the text of n approximately as a browser would render it, like the DOM's innerText.
+//Whitespace is collapsed outside of pre, listing, plaintext and textarea elements, block elements start on a new line,
+//paragraphs are separated by a blank line, br elements become line breaks and table cells are separated by tabs.
+//Elements that are not rendered by default, like script, style, template, head or elements with a hidden attribute, are skipped.
+func InnerText(n *html.Node) string {
+	c := &textCollector{lineStart: true}
+	Walk(n, c.enter, c.leave)
+	return c.b.String()
+}
+
+//Elements that are not rendered by default.
+var hiddenElements = map[atom.Atom]bool{
+	atom.Base:     true,
+	atom.Datalist: true,
+	atom.Head:     true,
+	atom.Link:     true,
+	atom.Meta:     true,
+	atom.Noscript: true,
+	atom.Param:    true,
+	atom.Script:   true,
+	atom.Source:   true,
+	atom.Style:    true,
+	atom.Template: true,
+	atom.Title:    true,
+	atom.Track:    true,
+}
+
+//Elements that are rendered as blocks by default.
+var blockElements = map[atom.Atom]bool{
+	atom.Address:    true,
+	atom.Article:    true,
+	atom.Aside:      true,
+	atom.Blockquote: true,
+	atom.Body:       true,
+	atom.Caption:    true,
+	atom.Center:     true,
+	atom.Dd:         true,
+	atom.Details:    true,
+	atom.Dialog:     true,
+	atom.Dir:        true,
+	atom.Div:        true,
+	atom.Dl:         true,
+	atom.Dt:         true,
+	atom.Fieldset:   true,
+	atom.Figcaption: true,
+	atom.Figure:     true,
+	atom.Footer:     true,
+	atom.Form:       true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Header:     true,
+	atom.Hgroup:     true,
+	atom.Hr:         true,
+	atom.Html:       true,
+	atom.Legend:     true,
+	atom.Li:         true,
+	atom.Listing:    true,
+	atom.Main:       true,
+	atom.Menu:       true,
+	atom.Nav:        true,
+	atom.Ol:         true,
+	atom.Optgroup:   true,
+	atom.Option:     true,
+	atom.P:          true,
+	atom.Plaintext:  true,
+	atom.Pre:        true,
+	atom.Section:    true,
+	atom.Summary:    true,
+	atom.Table:      true,
+	atom.Tr:         true,
+	atom.Ul:         true,
+	atom.Xmp:        true,
+}
+
+//Elements whose text keeps its whitespace.
+var preformattedElements = map[atom.Atom]bool{
+	atom.Listing:   true,
+	atom.Plaintext: true,
+	atom.Pre:       true,
+	atom.Textarea:  true,
+	atom.Xmp:       true,
+}
+
+//Elements whose whitespace-only text children are not rendered.
+var tableElements = map[atom.Atom]bool{
+	atom.Table: true,
+	atom.Tbody: true,
+	atom.Tfoot: true,
+	atom.Thead: true,
+	atom.Tr:    true,
+}
+
+func isHTMLElement(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Namespace == ""
+}
+
+//textCollector implements a simplified version of the rendered text collection steps of the innerText algorithm.
+type textCollector struct {
+	b         strings.Builder
+	breaks    int  //pending required line breaks
+	space     bool //pending collapsible space
+	lineStart bool //true at the start of the output or after a line break
+	pre       int  //nesting level of whitespace-preserving elements
+}
+
+func (c *textCollector) enter(n *html.Node) WalkAction {
+	switch n.Type {
+	case html.TextNode:
+		switch {
+		case c.pre > 0:
+			c.writeRaw(n.Data)
+		case n.Parent != nil && isHTMLElement(n.Parent) && tableElements[n.Parent.DataAtom] && strings.Trim(n.Data, asciiWhitespace) == "":
+			//whitespace between table parts is not rendered
+		default:
+			c.writeCollapsed(n.Data)
+		}
+		return Continue
+	case html.ElementNode:
+	default:
+		return Continue
+	}
+	if !isHTMLElement(n) {
+		return Continue
+	}
+	if hiddenElements[n.DataAtom] || HasAttr(n, "", "hidden") {
+		return SkipChildren
+	}
+	if n.DataAtom == atom.Br {
+		c.space = false
+		c.writeRaw("\n")
+	}
+	if preformattedElements[n.DataAtom] {
+		c.pre++
+	}
+	c.requireBreaks(n)
+	return Continue
+}
+
+func (c *textCollector) leave(n *html.Node) WalkAction {
+	if !isHTMLElement(n) || hiddenElements[n.DataAtom] || HasAttr(n, "", "hidden") {
+		return Continue
+	}
+	if preformattedElements[n.DataAtom] {
+		c.pre--
+	}
+	if n.DataAtom == atom.Td || n.DataAtom == atom.Th {
+		if s := NextElementSibling(n); s != nil && (s.DataAtom == atom.Td || s.DataAtom == atom.Th) {
+			c.space = false
+			c.writeRaw("\t")
+		}
+	}
+	c.requireBreaks(n)
+	return Continue
+}
+
+func (c *textCollector) requireBreaks(n *html.Node) {
+	k := 0
+	switch {
+	case n.DataAtom == atom.P:
+		k = 2
+	case blockElements[n.DataAtom]:
+		k = 1
+	}
+	if k > c.breaks {
+		c.breaks = k
+	}
+}
+
+//Emits pending line breaks or a pending space in front of new content.
+func (c *textCollector) flush() {
+	if c.b.Len() == 0 {
+		c.breaks = 0
+		c.space = false
+		return
+	}
+	if c.breaks > 0 {
+		for i := c.trailingNewlines(); i < c.breaks; i++ {
+			c.b.WriteByte('\n')
+		}
+		c.breaks = 0
+		c.space = false
+		c.lineStart = true
+	}
+	if c.space && !c.lineStart {
+		c.b.WriteByte(' ')
+	}
+	c.space = false
+}
+
+//Returns the number of newlines at the end of the output, up to the maximum of two required line breaks.
+func (c *textCollector) trailingNewlines() int {
+	s := c.b.String()
+	i := 0
+	for i < 2 && i < len(s) && s[len(s)-1-i] == '\n' {
+		i++
+	}
+	return i
+}
+
+func (c *textCollector) writeCollapsed(s string) {
+	for _, r := range s {
+		if strings.ContainsRune(asciiWhitespace, r) {
+			if !c.lineStart {
+				c.space = true
+			}
+			continue
+		}
+		c.flush()
+		c.b.WriteRune(r)
+		c.lineStart = false
+	}
+}
+
+func (c *textCollector) writeRaw(s string) {
+	if s == "" {
+		return
+	}
+	c.flush()
+	c.b.WriteString(s)
+	c.lineStart = strings.HasSuffix(s, "\n")
+}
+
+//The ASCII whitespace characters as defined by the HTML standard.
+const asciiWhitespace = " \t\n\f\r"
@@ -0,0 +1,63 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestTextContent(t *testing.T) {
+	const testDoc = "test.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ul := ElementByID(root, "siblings")
+	text := TextContent(ul)
+	for _, expect := range []string{"Sibling 1", "Sibling 2", "Sibling 3"} {
+		if !strings.Contains(text, expect) {
+			t.Errorf("Expected \"%s\" in text content %q", expect, text)
+		}
+	}
+	if strings.Contains(text, "comment") {
+		t.Error("TextContent must not include comments")
+	}
+	if !strings.HasPrefix(text, "\n\t\t") {
+		t.Errorf("TextContent must preserve whitespace, got %q", text)
+	}
+	leaf := &html.Node{Type: html.TextNode, Data: "leaf"}
+	if TextContent(leaf) != "leaf" {
+		t.Error("TextContent of a text node should be its data")
+	}
+}
+
+func TestInnerText(t *testing.T) {
+	const testDoc = "text.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expect = "The Title\n\n" +
+		"First paragraph with a link.\n\n" +
+		"Second\nline\n\n" +
+		"  keep\n    this  \n" +
+		"one\ntwo bold\n" +
+		"A\tB\n1\t2\n" +
+		"inline spans"
+	if got := InnerText(ElementByID(root, "article")); got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+	if got := InnerText(root); !strings.HasPrefix(got, "The Title") || strings.Contains(got, "color") {
+		t.Errorf("InnerText of the document must skip the head, got %q", got)
+	}
+	if got := InnerText(FirstElementByTag(root, atom.Em)); got != "Title" {
+		t.Errorf("Expected \"Title\", got %q", got)
+	}
+}
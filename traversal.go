@@ -0,0 +1,92 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/cond"
+	"github.com/jwdev42/rottensoup/internal/nav"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+//Traversal configures the order and depth in which the *With functions visit a tree.
+//The zero value is an unlimited depth-first traversal, the strategy used by all other search functions.
+type Traversal struct {
+	BreadthFirst bool //visit the tree level by level, so that shallower nodes are found first
+	MaxDepth     int  //if greater than zero, nodes more than MaxDepth levels below the start node are not visited
+}
+
+func (t Traversal) maxDepth() int {
+	if t.MaxDepth > 0 {
+		return t.MaxDepth
+	}
+	return -1
+}
+
+//Visits n and its descendants in the order given by t and passes each node together with its depth
+//relative to n to f. The traversal stops if f returns false.
+func Traverse(n *html.Node, t Traversal, f func(n *html.Node, depth int) bool) {
+	if t.BreadthFirst {
+		nav.BFS(n, t.maxDepth(), f)
+	} else {
+		nav.DFSDepth(n, t.maxDepth(), f, nil)
+	}
+}
+
+func (t Traversal) visit(n *html.Node, f func(*html.Node) bool) {
+	Traverse(n, t, func(n *html.Node, _ int) bool {
+		return f(n)
+	})
+}
+
+//Searches n and all of its descendants in the order given by t and returns the first node matched by m.
+//Returns nil if no such node was found.
+func FindWith(n *html.Node, t Traversal, m Matcher) *html.Node {
+	nodes := make([]*html.Node, 0, 1)
+	t.visit(n, cond.Match(&nodes, true, m))
+	if len(nodes) > 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+//Searches n and all of its descendants in the order given by t and returns all nodes matched by m.
+//Returns nil if no matches were found.
+func FindAllWith(n *html.Node, t Traversal, m Matcher) []*html.Node {
+	nodes := make([]*html.Node, 0, 10)
+	t.visit(n, cond.Match(&nodes, false, m))
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes
+}
+
+//Like FirstElementByAttr, but visits the tree in the order given by t.
+func FirstElementByAttrWith(n *html.Node, t Traversal, attr ...html.Attribute) *html.Node {
+	return FindWith(n, t, Attr(attr...))
+}
+
+//Like FirstElementByClassName, but visits the tree in the order given by t.
+func FirstElementByClassNameWith(n *html.Node, t Traversal, name ...string) *html.Node {
+	return FindWith(n, t, Class(name...))
+}
+
+//Like FirstElementByTag, but visits the tree in the order given by t.
+func FirstElementByTagWith(n *html.Node, t Traversal, tag ...atom.Atom) *html.Node {
+	return FindWith(n, t, Tag(tag...))
+}
+
+//Like ElementsByAttr, but visits the tree in the order given by t.
+func ElementsByAttrWith(n *html.Node, t Traversal, attr ...html.Attribute) []*html.Node {
+	return FindAllWith(n, t, Attr(attr...))
+}
+
+//Like ElementsByClassName, but visits the tree in the order given by t.
+func ElementsByClassNameWith(n *html.Node, t Traversal, name ...string) []*html.Node {
+	return FindAllWith(n, t, Class(name...))
+}
+
+//Like ElementsByTag, but visits the tree in the order given by t.
+func ElementsByTagWith(n *html.Node, t Traversal, tag ...atom.Atom) []*html.Node {
+	return FindAllWith(n, t, Tag(tag...))
+}
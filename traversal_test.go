@@ -0,0 +1,79 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"testing"
+)
+
+func TestFindWith(t *testing.T) {
+	const testDoc = "traversal.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := FirstElementByClassNameWith(root, Traversal{}, "x"); e == nil || AttrVal(e, "", "id") != "deep" {
+		t.Error("Depth-first search should find the nested element first")
+	}
+	if e := FirstElementByClassNameWith(root, Traversal{BreadthFirst: true}, "x"); e == nil || AttrVal(e, "", "id") != "shallow" {
+		t.Error("Breadth-first search should find the shallowest element first")
+	}
+	expectIDs(t, "ElementsByClassNameWith", ElementsByClassNameWith(root, Traversal{BreadthFirst: true}, "x"), "shallow", "deep")
+
+	menu := ElementByID(root, "menu")
+	expectIDs(t, "ElementsByTagWith depth-first", ElementsByTagWith(menu, Traversal{MaxDepth: 3}, atom.A), "m1", "m3")
+	expectIDs(t, "ElementsByTagWith breadth-first", ElementsByTagWith(menu, Traversal{BreadthFirst: true}, atom.A), "m1", "m3", "m2")
+	expectIDs(t, "ElementsByTagWith unlimited", ElementsByTagWith(menu, Traversal{}, atom.A), "m1", "m2", "m3")
+	if e := FirstElementByTagWith(menu, Traversal{MaxDepth: 2}, atom.A); e != nil {
+		t.Error("Expected return value nil")
+	}
+	if e := FirstElementByAttrWith(root, Traversal{BreadthFirst: true, MaxDepth: 5}, html.Attribute{Key: "href", Val: "/2"}); e != nil {
+		t.Error("Expected return value nil")
+	}
+	expectIDs(t, "ElementsByAttrWith", ElementsByAttrWith(root, Traversal{BreadthFirst: true}, html.Attribute{Key: "href", Val: "/2"}), "m2")
+}
+
+func TestTraverse(t *testing.T) {
+	const testDoc = "traversal.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bf := range []bool{false, true} {
+		prev := 0
+		count := 0
+		Traverse(root, Traversal{BreadthFirst: bf}, func(n *html.Node, depth int) bool {
+			expect := 0
+			for p := n.Parent; p != nil; p = p.Parent {
+				expect++
+			}
+			if depth != expect {
+				t.Errorf("Expected depth %d for %q, got %d", expect, n.Data, depth)
+			}
+			if bf && depth < prev {
+				t.Error("Breadth-first traversal visited a shallower node after a deeper one")
+			}
+			prev = depth
+			count++
+			return true
+		})
+		if all := len(FindAll(root, And())); count != all {
+			t.Errorf("Expected %d visited nodes, got %d", all, count)
+		}
+	}
+
+	count := 0
+	Traverse(root, Traversal{BreadthFirst: true}, func(*html.Node, int) bool {
+		count++
+		return count < 4
+	})
+	if count != 4 {
+		t.Errorf("Expected traversal to stop after 4 nodes, got %d", count)
+	}
+}
@@ -0,0 +1,299 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+//URLKind describes the syntax a URL was found in.
+type URLKind int
+
+const (
+	PlainURL   URLKind = iota //the attribute value is a URL, or a space-separated list of URLs like ping
+	SrcsetURL                 //an image candidate of a srcset attribute
+	RefreshURL                //the URL of the content attribute of a meta refresh element
+	StyleURL                  //a url() token of a style attribute or a style element
+)
+
+//URLContext tells the callback of RewriteURLs where a URL was found.
+type URLContext struct {
+	Node *html.Node //the element that contains the URL
+	Attr string     //the attribute that contains the URL, empty for the text of style elements
+	Kind URLKind
+}
+
+//URL attributes of HTML elements, attributes holding space-separated lists of URLs and srcset attributes are handled separately.
+var urlAttrs = map[atom.Atom][]string{
+	atom.A:          {"href"},
+	atom.Area:       {"href"},
+	atom.Audio:      {"src"},
+	atom.Base:       {"href"},
+	atom.Blockquote: {"cite"},
+	atom.Body:       {"background"},
+	atom.Button:     {"formaction"},
+	atom.Del:        {"cite"},
+	atom.Embed:      {"src"},
+	atom.Form:       {"action"},
+	atom.Frame:      {"src", "longdesc"},
+	atom.Html:       {"manifest"},
+	atom.Iframe:     {"src", "longdesc"},
+	atom.Img:        {"src", "longdesc"},
+	atom.Input:      {"src", "formaction"},
+	atom.Ins:        {"cite"},
+	atom.Link:       {"href"},
+	atom.Object:     {"data", "codebase"},
+	atom.Q:          {"cite"},
+	atom.Script:     {"src"},
+	atom.Source:     {"src"},
+	atom.Table:      {"background"},
+	atom.Td:         {"background"},
+	atom.Th:         {"background"},
+	atom.Track:      {"src"},
+	atom.Video:      {"src", "poster"},
+}
+
+//Passes every URL in doc and its descendants to f and replaces it by the URL f returns. The original text is kept if f returns nil.
+//Covers the URL attributes of HTML elements, the ping attribute, srcset candidates of img and source elements,
+//the content attribute of meta refresh elements, href and xlink:href of SVG elements and url() tokens in style attributes
+//and style elements. URLs are passed as found in the document and are not resolved, URLs that cannot be parsed are skipped.
+func RewriteURLs(doc *html.Node, f func(u *url.URL, ctx URLContext) *url.URL) {
+	Walk(doc, func(n *html.Node) WalkAction {
+		if n.Type != html.ElementNode {
+			return Continue
+		}
+		if isHTMLElement(n) && n.DataAtom == atom.Style {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.TextNode {
+					c.Data = rewriteStyle(c.Data, f, URLContext{Node: n, Kind: StyleURL})
+				}
+			}
+			return SkipChildren
+		}
+		for i := range n.Attr {
+			rewriteAttr(n, &n.Attr[i], f)
+		}
+		return Continue
+	}, nil)
+}
+
+func rewriteAttr(n *html.Node, a *html.Attribute, f func(*url.URL, URLContext) *url.URL) {
+	ctx := URLContext{Node: n, Attr: a.Key}
+	if a.Key == "style" && a.Namespace == "" {
+		ctx.Kind = StyleURL
+		a.Val = rewriteStyle(a.Val, f, ctx)
+		return
+	}
+	if n.Namespace == "svg" {
+		if a.Key == "href" && (a.Namespace == "" || a.Namespace == "xlink") {
+			a.Val = rewriteURL(a.Val, f, ctx)
+		}
+		return
+	}
+	if n.Namespace != "" || a.Namespace != "" {
+		return
+	}
+	switch {
+	case a.Key == "ping" && (n.DataAtom == atom.A || n.DataAtom == atom.Area):
+		urls := strings.Fields(a.Val)
+		for i := range urls {
+			urls[i] = rewriteURL(urls[i], f, ctx)
+		}
+		a.Val = strings.Join(urls, " ")
+	case a.Key == "srcset" && (n.DataAtom == atom.Img || n.DataAtom == atom.Source):
+		ctx.Kind = SrcsetURL
+		candidates := parseSrcset(a.Val)
+		changed := false
+		for i, c := range candidates {
+			candidates[i].URL = rewriteURL(c.URL, f, ctx)
+			changed = changed || candidates[i].URL != c.URL
+		}
+		if changed {
+			a.Val = formatSrcset(candidates)
+		}
+	case a.Key == "content" && n.DataAtom == atom.Meta:
+		if v, ok := attrValFold(n, "http-equiv"); !ok || !strings.EqualFold(strings.Trim(v, asciiWhitespace), "refresh") {
+			return
+		}
+		start, end, ok := refreshURL(a.Val)
+		if ok {
+			ctx.Kind = RefreshURL
+			a.Val = a.Val[:start] + rewriteURL(a.Val[start:end], f, ctx) + a.Val[end:]
+		}
+	default:
+		for _, key := range urlAttrs[n.DataAtom] {
+			if a.Key == key {
+				a.Val = rewriteURL(a.Val, f, ctx)
+				return
+			}
+		}
+	}
+}
+
+//Returns the text that replaces the URL s.
+func rewriteURL(s string, f func(*url.URL, URLContext) *url.URL, ctx URLContext) string {
+	u, err := parseURLAttr(s)
+	if err != nil {
+		return s
+	}
+	if r := f(u, ctx); r != nil {
+		return r.String()
+	}
+	return s
+}
+
+var cssURL = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^\s"'()]*))\s*\)`)
+
+func rewriteStyle(css string, f func(*url.URL, URLContext) *url.URL, ctx URLContext) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range cssURL.FindAllStringSubmatchIndex(css, -1) {
+		quote, group := "", 3
+		switch {
+		case m[2] >= 0:
+			quote, group = `"`, 1
+		case m[4] >= 0:
+			quote, group = "'", 2
+		}
+		s := css[m[2*group]:m[2*group+1]]
+		r := rewriteURL(s, f, ctx)
+		if r == s {
+			continue
+		}
+		if quote == "" && strings.ContainsAny(r, "\"'()\\"+asciiWhitespace) {
+			quote = `"`
+		}
+		if quote != "" {
+			r = strings.NewReplacer(`\`, `\\`, quote, `\`+quote).Replace(r)
+		}
+		b.WriteString(css[last:m[0]])
+		b.WriteString("url(" + quote + r + quote + ")")
+		last = m[1]
+	}
+	if last == 0 {
+		return css
+	}
+	b.WriteString(css[last:])
+	return b.String()
+}
+
+//Returns the start and end offset of the URL in the content attribute of a meta refresh element,
+//as determined by the shared declarative refresh steps of the HTML standard.
+func refreshURL(content string) (start, end int, ok bool) {
+	i := 0
+	skip := func(chars string) {
+		for i < len(content) && strings.IndexByte(chars, content[i]) >= 0 {
+			i++
+		}
+	}
+	skip(asciiWhitespace)
+	digits := i
+	skip("0123456789")
+	if i == digits && (i == len(content) || content[i] != '.') {
+		return 0, 0, false
+	}
+	skip("0123456789.")
+	skip(asciiWhitespace)
+	if i < len(content) && (content[i] == ';' || content[i] == ',') {
+		i++
+	}
+	skip(asciiWhitespace)
+	if i+3 <= len(content) && strings.EqualFold(content[i:i+3], "url") {
+		i += 3
+		skip(asciiWhitespace)
+		if i < len(content) && content[i] == '=' {
+			i++
+			skip(asciiWhitespace)
+		}
+	}
+	start, end = i, len(content)
+	if start < end && (content[start] == '"' || content[start] == '\'') {
+		start++
+		if q := strings.IndexByte(content[start:], content[start-1]); q >= 0 {
+			end = start + q
+		}
+	}
+	end = start + len(strings.TrimRight(content[start:end], asciiWhitespace))
+	return start, end, start < end
+}
+
+//srcsetCandidate is an image candidate of a srcset attribute.
+type srcsetCandidate struct {
+	URL        string
+	Descriptor string //the width or density descriptor, may be empty
+}
+
+//Splits a srcset attribute into image candidates according to the HTML standard. Candidates without URL are dropped.
+func parseSrcset(s string) []srcsetCandidate {
+	var candidates []srcsetCandidate
+	i := 0
+	for i < len(s) {
+		for i < len(s) && (s[i] == ',' || strings.IndexByte(asciiWhitespace, s[i]) >= 0) {
+			i++
+		}
+		start := i
+		for i < len(s) && strings.IndexByte(asciiWhitespace, s[i]) < 0 {
+			i++
+		}
+		u := s[start:i]
+		if u == "" {
+			break
+		}
+		c := srcsetCandidate{URL: strings.TrimRight(u, ",")}
+		if !strings.HasSuffix(u, ",") {
+			start = i
+			depth := 0
+			for ; i < len(s); i++ {
+				if s[i] == '(' {
+					depth++
+				} else if s[i] == ')' && depth > 0 {
+					depth--
+				} else if s[i] == ',' && depth == 0 {
+					break
+				}
+			}
+			c.Descriptor = collapseSpace(s[start:i])
+		}
+		if c.URL != "" {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+func formatSrcset(candidates []srcsetCandidate) string {
+	parts := make([]string, len(candidates))
+	for i, c := range candidates {
+		parts[i] = c.URL
+		if c.Descriptor != "" {
+			parts[i] += " " + c.Descriptor
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+//Resolves every relative URL in doc and its descendants, see RewriteURLs. URLs are resolved against the document's
+//base URL as returned by BaseURL, except for the href of base elements, which is resolved against pageURL.
+//Absolute URLs are left untouched.
+func Absolutize(doc *html.Node, pageURL *url.URL) {
+	base := BaseURL(doc, pageURL)
+	RewriteURLs(doc, func(u *url.URL, ctx URLContext) *url.URL {
+		if u.IsAbs() {
+			return nil
+		}
+		if isHTMLElement(ctx.Node) && ctx.Node.DataAtom == atom.Base {
+			if pageURL == nil {
+				return nil
+			}
+			return pageURL.ResolveReference(u)
+		}
+		if base == nil {
+			return nil
+		}
+		return base.ResolveReference(u)
+	})
+}
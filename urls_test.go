@@ -0,0 +1,153 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRewriteURLs(t *testing.T) {
+	const testDoc = "urls.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var found []string
+	kinds := make(map[URLKind]int)
+	RewriteURLs(root, func(u *url.URL, ctx URLContext) *url.URL {
+		found = append(found, u.String())
+		kinds[ctx.Kind]++
+		if ctx.Kind == StyleURL && ctx.Attr == "" && ctx.Node.DataAtom != atom.Style {
+			t.Error("URLs in style elements must be passed with the style element as node")
+		}
+		return nil
+	})
+	expect := []string{
+		"app.appcache", "../site/", "next.html", "style.css", "bg.png", "img/a%20b.png", "body.png",
+		"page.html#frag", "/ping1", "ping2", "https://example.org/x?y=1", "mailto:someone@example.org",
+		"img.png", "img-1x.png", "img,2x.png", "data:image/png;base64,AAA=", "div.png",
+		"submit", "alt", "quote.html", "poster.jpg", "movie.mp4", "flash.swf", "svg.png", "sprite.svg#icon",
+	}
+	if strings.Join(found, " ") != strings.Join(expect, " ") {
+		t.Errorf("Expected URLs %q, got %q", expect, found)
+	}
+	if kinds[SrcsetURL] != 3 || kinds[RefreshURL] != 1 || kinds[StyleURL] != 3 {
+		t.Errorf("Unexpected URL kinds %v", kinds)
+	}
+
+	before := renderString(t, root)
+	RewriteURLs(root, func(*url.URL, URLContext) *url.URL {
+		return nil
+	})
+	if after := renderString(t, root); after != before {
+		t.Error("The document must not change if the callback returns nil")
+	}
+}
+
+func TestAbsolutize(t *testing.T) {
+	const testDoc = "urls.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := url.Parse("https://example.net/a/b/index.html")
+	Absolutize(root, page)
+
+	tests := []struct {
+		id, key, val string
+	}{
+		{"link", "href", "https://example.net/a/site/page.html#frag"},
+		{"link", "ping", "https://example.net/ping1 https://example.net/a/site/ping2"},
+		{"abs", "href", "https://example.org/x?y=1"},
+		{"mail", "href", "mailto:someone@example.org"},
+		{"img", "srcset", "https://example.net/a/site/img-1x.png 1x, https://example.net/a/site/img,2x.png 2x, data:image/png;base64,AAA= 3x"},
+		{"styled", "style", "background: url('https://example.net/a/site/div.png')"},
+		{"form", "action", "https://example.net/a/site/submit"},
+	}
+	for _, test := range tests {
+		if v := AttrVal(ElementByID(root, test.id), "", test.key); v != test.val {
+			t.Errorf("Expected %s of #%s to be \"%s\", got \"%s\"", test.key, test.id, test.val, v)
+		}
+	}
+	if v := AttrVal(FirstElementByTag(root, atom.Base), "", "href"); v != "https://example.net/a/site/" {
+		t.Errorf("The base element must be resolved against the page URL, got \"%s\"", v)
+	}
+	metas := ElementsByTag(root, atom.Meta)
+	if v := AttrVal(metas[0], "", "content"); v != "5; URL='https://example.net/a/site/next.html'" {
+		t.Errorf("Unexpected refresh content \"%s\"", v)
+	}
+	if v := AttrVal(metas[1], "", "content"); v != "5; url=unchanged.html" {
+		t.Errorf("Meta elements without http-equiv must not be changed, got \"%s\"", v)
+	}
+	css := FirstElementByTag(root, atom.Style).FirstChild.Data
+	if css != `body { background: url(https://example.net/a/site/bg.png) } .a { background-image: url("https://example.net/a/site/img/a%20b.png") }` {
+		t.Errorf("Unexpected style element text %s", css)
+	}
+	if v := AttrVal(FirstElementByTag(root, atom.P), "", "title"); v != "title.html" {
+		t.Error("Attributes that are no URL attributes must not be changed")
+	}
+	for _, e := range FindAll(root, func(n *html.Node) bool {
+		return n.Type == html.ElementNode && n.Namespace == "svg" && len(n.Attr) > 0
+	}) {
+		if !strings.HasPrefix(e.Attr[0].Val, "https://example.net/a/site/") {
+			t.Errorf("SVG link was not resolved: %s", e.Attr[0].Val)
+		}
+	}
+	a := &html.Node{Type: html.ElementNode, Data: "a", DataAtom: atom.A, Attr: []html.Attribute{{Key: "href", Val: "x.html"}}}
+	Absolutize(a, page)
+	if v := AttrVal(a, "", "href"); v != "https://example.net/a/b/x.html" {
+		t.Errorf("The URLs of doc itself must be rewritten, got \"%s\"", v)
+	}
+}
+
+func TestRefreshURL(t *testing.T) {
+	tests := []struct {
+		content, url string
+	}{
+		{"5; url=next.html", "next.html"},
+		{"0;URL = 'quoted.html' ", "quoted.html"},
+		{"3.5, \"dq.html\"", "dq.html"},
+		{"1 plain.html ", "plain.html"},
+		{"10", ""},
+		{"; url=missing-time.html", ""},
+	}
+	for _, test := range tests {
+		start, end, ok := refreshURL(test.content)
+		if got := test.content[start:end]; got != test.url || ok != (test.url != "") {
+			t.Errorf("%q: expected URL \"%s\", got \"%s\"", test.content, test.url, got)
+		}
+	}
+}
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		srcset, expect string
+	}{
+		{"a.png", "a.png"},
+		{" a.png 1x ,b.png   2x", "a.png 1x, b.png 2x"},
+		{"a.png,b.png 100w", "a.png,b.png 100w"},
+		{"a.png, b.png 100w", "a.png, b.png 100w"},
+		{"a,b.png 1x, c.png (foo, bar) 2x", "a,b.png 1x, c.png (foo, bar) 2x"},
+		{" , ,", ""},
+	}
+	for _, test := range tests {
+		if s := formatSrcset(parseSrcset(test.srcset)); s != test.expect {
+			t.Errorf("%q: expected \"%s\", got \"%s\"", test.srcset, test.expect, s)
+		}
+	}
+}
+
+func renderString(t *testing.T, n *html.Node) string {
+	var b strings.Builder
+	if err := html.Render(&b, n); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
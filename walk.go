@@ -0,0 +1,36 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"github.com/jwdev42/rottensoup/internal/nav"
+	"golang.org/x/net/html"
+)
+
+//WalkAction is returned by the callbacks of Walk to control how the walk proceeds.
+type WalkAction int
+
+const (
+	Continue     WalkAction = WalkAction(nav.Continue)     //proceed normally
+	SkipChildren WalkAction = WalkAction(nav.SkipChildren) //do not descend into the children of the current node
+	Stop         WalkAction = WalkAction(nav.Stop)         //end the walk immediately
+)
+
+//Walks n and its descendants depth-first. pre is called when a node is entered, post when it is left,
+//either may be nil. If pre returns SkipChildren, the node's subtree is not visited but the walk continues
+//with its next sibling, post is still called for the skipped node. Returning Stop from either callback ends the walk.
+//Returns false if the walk was stopped.
+func Walk(n *html.Node, pre, post func(*html.Node) WalkAction) bool {
+	var navPre, navPost func(*html.Node) nav.Action
+	if pre != nil {
+		navPre = func(n *html.Node) nav.Action {
+			return nav.Action(pre(n))
+		}
+	}
+	if post != nil {
+		navPost = func(n *html.Node) nav.Action {
+			return nav.Action(post(n))
+		}
+	}
+	return nav.Walk(n, navPre, navPost)
+}
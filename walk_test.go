@@ -0,0 +1,73 @@
+//This file is part of rottensoup ©2021 Jörg Walter
+
+package rottensoup
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"strings"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	const testDoc = "walk.html"
+
+	root, err := parseTestFile(testDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := FirstElementByTag(root, atom.Body)
+	var text []string
+	var left []string
+	pre := func(n *html.Node) WalkAction {
+		switch {
+		case n.Type == html.TextNode:
+			text = append(text, n.Data)
+		case n.DataAtom == atom.Script, n.DataAtom == atom.Svg, n.DataAtom == atom.Template:
+			return SkipChildren
+		}
+		return Continue
+	}
+	post := func(n *html.Node) WalkAction {
+		if n.Type == html.ElementNode {
+			left = append(left, n.Data)
+		}
+		return Continue
+	}
+	if !Walk(root, pre, post) {
+		t.Error("Walk reported a stop although no callback returned Stop")
+	}
+	if got := strings.Join(text, "|"); !strings.Contains(got, "visible 1|visible 2|visible 3") || strings.Contains(got, "svg") || strings.Contains(got, "template") || strings.Contains(got, "script") {
+		t.Errorf("Skipped subtrees were visited: %q", got)
+	}
+	if got := strings.Join(left, " "); !strings.Contains(got, "script") || !strings.Contains(got, "p svg template p p body html") {
+		t.Errorf("Unexpected post-order sequence %q", got)
+	}
+
+	visited := 0
+	stopped := Walk(body, func(n *html.Node) WalkAction {
+		visited++
+		if n.Type == html.TextNode && n.Data == "visible 2" {
+			return Stop
+		}
+		return Continue
+	}, nil)
+	if stopped {
+		t.Error("Walk should return false when stopped")
+	}
+	if visited != 11 {
+		t.Errorf("Expected 11 visited nodes before stopping, got %d", visited)
+	}
+
+	left = left[:0]
+	Walk(body, nil, func(n *html.Node) WalkAction {
+		if n.DataAtom == atom.Svg {
+			return Stop
+		}
+		return post(n)
+	})
+	if got := strings.Join(left, " "); got != "p text" {
+		t.Errorf("Expected post-order walk to stop at the svg element, got %q", got)
+	}
+}